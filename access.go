@@ -0,0 +1,64 @@
+package rawdata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkAllowedRoot returns an error if an allowed root directory is set and
+// the file is outside of it; on the local filesystem, symbolic links are
+// evaluated first, so that they cannot be used to point outside the root.
+func checkAllowedRoot(filename string, o *options) error {
+	if o.allowedRoot == "" {
+		return nil
+	}
+	root, err := canonicalPath(o.allowedRoot, o)
+	if err != nil {
+		return fmt.Errorf("error resolving allowed root '%s': %w", o.allowedRoot, err)
+	}
+	target, err := canonicalPath(filename, o)
+	if err != nil {
+		return fmt.Errorf("error resolving file '%s': %w", filename, err)
+	}
+	if relative, err := filepath.Rel(root, target); err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("file '%s' is outside '%s': %w", filename, o.allowedRoot, ErrPathEscape)
+	}
+	return nil
+}
+
+// checkSymlink returns an error if following symbolic links is disabled and
+// the file is a symbolic link; it only applies to the local filesystem, since
+// fs.FS has no notion of symbolic links.
+func checkSymlink(filename string, o *options) error {
+	if o.followSymlinks || o.fsys != nil {
+		return nil
+	}
+	info, err := os.Lstat(filename)
+	if err != nil {
+		// let the caller report missing files
+		return nil
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("file '%s': %w", filename, ErrSymlink)
+	}
+	return nil
+}
+
+// canonicalPath returns the absolute, cleaned version of the path; on the
+// local filesystem, symbolic links are evaluated too, if the path exists.
+func canonicalPath(name string, o *options) (string, error) {
+	if o.fsys != nil {
+		// fs.FS paths are always relative to the root of the filesystem
+		return filepath.Join(string(filepath.Separator), filepath.FromSlash(name)), nil
+	}
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(name); err == nil {
+		name = resolved
+	}
+	return name, nil
+}
@@ -0,0 +1,83 @@
+package rawdata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithAllowedRoot(t *testing.T) {
+	if _, err := Unmarshal("@./test/struct.json", WithAllowedRoot("./test")); err != nil {
+		t.Fatalf("error unmarshalling file inside the allowed root: %v", err)
+	}
+	if _, err := Unmarshal("@struct.json", WithBaseDir("./test"), WithAllowedRoot("./test")); err != nil {
+		t.Fatalf("error unmarshalling file inside the allowed root: %v", err)
+	}
+	for _, input := range []string{
+		"@./test/../unmarshal_test.go",
+		"@../rawdata/test/struct.json",
+		"@/etc/passwd",
+		"@./test/rules/../../go.mod",
+	} {
+		if _, err := Unmarshal(input, WithAllowedRoot("./test")); !errors.Is(err, ErrPathEscape) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, ErrPathEscape, err)
+		}
+	}
+	if _, err := Unmarshal("@./test/rules/*.yaml", WithAllowedRoot("./test/include")); !errors.Is(err, ErrPathEscape) {
+		t.Errorf("invalid error for glob: expected %v, got %v", ErrPathEscape, err)
+	}
+}
+
+func TestWithAllowedRootSymlink(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.json"), []byte(`{"secret": true}`), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.json"), filepath.Join(root, "link.json")); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+	if _, err := Unmarshal("@"+filepath.Join(root, "link.json"), WithAllowedRoot(root)); !errors.Is(err, ErrPathEscape) {
+		t.Fatalf("invalid error for symlink escaping the root: expected %v, got %v", ErrPathEscape, err)
+	}
+}
+
+func TestWithFollowSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.json"), []byte(`{"name": "John"}`), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.json"), []byte(`{"secret": true}`), 0600); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	inside := filepath.Join(root, "inside.json")
+	if err := os.Symlink(filepath.Join(root, "app.json"), inside); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+	escaping := filepath.Join(root, "escaping.json")
+	if err := os.Symlink(filepath.Join(outside, "secret.json"), escaping); err != nil {
+		t.Fatalf("error creating symbolic link: %v", err)
+	}
+
+	// links are followed by default, within the allowed root
+	if _, err := Unmarshal("@"+inside, WithAllowedRoot(root)); err != nil {
+		t.Errorf("error unmarshalling symlink inside the root: %v", err)
+	}
+	if _, err := Unmarshal("@"+escaping, WithAllowedRoot(root), WithFollowSymlinks(true)); !errors.Is(err, ErrPathEscape) {
+		t.Errorf("invalid error for symlink escaping the root: %v", err)
+	}
+
+	for _, link := range []string{inside, escaping} {
+		if _, err := Unmarshal("@"+link, WithFollowSymlinks(false)); !errors.Is(err, ErrSymlink) {
+			t.Errorf("invalid error for symlink %s: expected %v, got %v", link, ErrSymlink, err)
+		}
+		if _, err := Unmarshal("@"+link, WithFollowSymlinks(false), WithCache(NewMemoryCache())); !errors.Is(err, ErrSymlink) {
+			t.Errorf("invalid error for cached symlink %s: expected %v, got %v", link, ErrSymlink, err)
+		}
+	}
+	if _, err := Unmarshal("@"+filepath.Join(root, "app.json"), WithFollowSymlinks(false)); err != nil {
+		t.Errorf("error unmarshalling regular file: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build afero
+// +build afero
+
+package rawdata
+
+import (
+	"io/fs"
+	"path"
+
+	"github.com/spf13/afero"
+)
+
+// WithAferoFs sets the afero filesystem file references are read from and
+// MarshalToFile writes to, e.g. an afero.MemMapFs in unit tests; as with
+// WithFS, paths are resolved against the root of the filesystem (e.g.
+// "@./config/app.yaml" refers to "/config/app.yaml"), so to expose a directory
+// on disk wrap it in an afero.BasePathFs. WithFS and WithAferoFs override
+// each other: if both are given, the last one wins. This option is only
+// available when building with the "afero" tag.
+func WithAferoFs(afs afero.Fs) Option {
+	return func(o *options) {
+		o.fsys = aferoFS{afs: afs}
+		o.writeFile = func(filename string, data []byte, perm fs.FileMode) error {
+			return afero.WriteFile(afs, rooted(filename), data, perm)
+		}
+	}
+}
+
+// aferoFS is an fs.FS backed by an afero filesystem.
+type aferoFS struct {
+	afs afero.Fs
+}
+
+// Open opens the named file.
+func (f aferoFS) Open(name string) (fs.File, error) {
+	return f.afs.Open(rooted(name))
+}
+
+// Stat returns information about the named file.
+func (f aferoFS) Stat(name string) (fs.FileInfo, error) {
+	return f.afs.Stat(rooted(name))
+}
+
+// Glob returns the names of the files matching the given pattern, in the
+// same form as the pattern.
+func (f aferoFS) Glob(pattern string) ([]string, error) {
+	matches, err := afero.Glob(f.afs, rooted(pattern))
+	if err != nil {
+		return nil, err
+	}
+	for i, match := range matches {
+		matches[i] = match[1:]
+	}
+	return matches, nil
+}
+
+// rooted converts an fs.FS path into an absolute afero path.
+func rooted(name string) string {
+	return path.Join("/", name)
+}
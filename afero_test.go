@@ -0,0 +1,60 @@
+//go:build afero
+// +build afero
+
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestWithAferoFs(t *testing.T) {
+	afs := afero.NewMemMapFs()
+	afero.WriteFile(afs, "/config/app.yaml", []byte("name: John\n"), 0644)
+	afero.WriteFile(afs, "/config/rules/a.json", []byte(`{"id": 1}`), 0644)
+	afero.WriteFile(afs, "/config/rules/b.json", []byte(`{"id": 2}`), 0644)
+
+	for _, value := range []string{"@config/app.yaml", "@/config/app.yaml", "@./config/../config/app.yaml"} {
+		result, err := Unmarshal(value, WithAferoFs(afs))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", value, err)
+		}
+		if !reflect.DeepEqual(result, map[string]interface{}{"name": "John"}) {
+			t.Errorf("invalid result for %q: %v", value, result)
+		}
+	}
+
+	result, err := Unmarshal("@config/rules/*.json", WithAferoFs(afs))
+	if err != nil {
+		t.Fatalf("error unmarshalling glob: %v", err)
+	}
+	if array, ok := result.([]interface{}); !ok || len(array) != 2 {
+		t.Errorf("invalid glob result: %v", result)
+	}
+
+	if _, err := Unmarshal("@config/missing.yaml", WithAferoFs(afs)); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for missing file: %v", err)
+	}
+	// the last filesystem option wins
+	if _, err := Unmarshal("@config/app.yaml", WithAferoFs(afs), WithFS(afero.NewIOFS(afero.NewMemMapFs()))); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error with overriding filesystem: %v", err)
+	}
+}
+
+func TestMarshalToFileWithAferoFs(t *testing.T) {
+	afs := afero.NewMemMapFs()
+	value := map[string]interface{}{"name": "John"}
+	if err := MarshalToFile(value, "out/app.json", WithAferoFs(afs)); err != nil {
+		t.Fatalf("error marshalling to afero filesystem: %v", err)
+	}
+	if exists, _ := afero.Exists(afs, "/out/app.json"); !exists {
+		t.Fatal("file not written to afero filesystem")
+	}
+	result, err := Unmarshal("@out/app.json", WithAferoFs(afs))
+	if err != nil || !reflect.DeepEqual(result, value) {
+		t.Errorf("invalid round-trip: %v (error: %v)", result, err)
+	}
+}
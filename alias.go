@@ -0,0 +1,83 @@
+package rawdata
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkYAMLAliases returns an error if the aliases in the YAML document would
+// expand to more nodes than allowed.
+func checkYAMLAliases(document *yaml.Node, o *options) error {
+	if o.maxAliasExpansion <= 0 {
+		return nil
+	}
+	counter := aliasCounter{
+		limit: o.maxAliasExpansion,
+		sizes: map[*yaml.Node]int{},
+	}
+	if counter.expansion(document) > o.maxAliasExpansion {
+		return fmt.Errorf("YAML aliases expand to more than %d nodes: %w", o.maxAliasExpansion, ErrAliasBudgetExceeded)
+	}
+	return nil
+}
+
+// aliasCounter counts the nodes that aliases expand to; all counts saturate
+// just above the limit, so that they cannot overflow however many levels of
+// aliases there are.
+type aliasCounter struct {
+	limit int
+	// sizes holds the size of each node reached through an alias, so that
+	// each node is only walked once; a size of -1 marks a node that is
+	// being walked, so that recursive aliases do not loop forever.
+	sizes map[*yaml.Node]int
+}
+
+// expansion returns the number of nodes that the aliases under the given
+// node expand to.
+func (c *aliasCounter) expansion(node *yaml.Node) int {
+	if node.Kind == yaml.AliasNode {
+		if node.Alias == nil {
+			return 0
+		}
+		return c.size(node.Alias)
+	}
+	total := 0
+	for _, child := range node.Content {
+		total = c.add(total, c.expansion(child))
+	}
+	return total
+}
+
+// size returns the number of nodes in the given node, including itself and
+// the nodes that the aliases under it expand to.
+func (c *aliasCounter) size(node *yaml.Node) int {
+	if size, ok := c.sizes[node]; ok {
+		if size < 0 {
+			// recursive alias, rejected by the decoder anyway
+			return 0
+		}
+		return size
+	}
+	c.sizes[node] = -1
+	total := 1
+	if node.Kind == yaml.AliasNode {
+		if node.Alias != nil {
+			total = c.size(node.Alias)
+		}
+	} else {
+		for _, child := range node.Content {
+			total = c.add(total, c.size(child))
+		}
+	}
+	c.sizes[node] = total
+	return total
+}
+
+// add returns the sum of the two counts, saturating just above the limit.
+func (c *aliasCounter) add(a, b int) int {
+	if a+b > c.limit {
+		return c.limit + 1
+	}
+	return a + b
+}
@@ -0,0 +1,55 @@
+package rawdata
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// billionLaughs returns a YAML document with the given number of levels of
+// aliases, each referring to ten copies of the one before.
+func billionLaughs(levels int) string {
+	var document strings.Builder
+	document.WriteString("---\nl0: &l0 \"lol\"\n")
+	for level := 1; level <= levels; level++ {
+		references := make([]string, 10)
+		for i := range references {
+			references[i] = fmt.Sprintf("*l%d", level-1)
+		}
+		fmt.Fprintf(&document, "l%d: &l%d [%s]\n", level, level, strings.Join(references, ", "))
+	}
+	return document.String()
+}
+
+func TestWithMaxAliasExpansion(t *testing.T) {
+	tests := []struct {
+		input  string
+		budget int
+		fails  bool
+	}{
+		{billionLaughs(9), DefaultMaxAliasExpansion, true},
+		{billionLaughs(4), DefaultMaxAliasExpansion, false},
+		{billionLaughs(4), 1000, true},
+		{billionLaughs(4), 0, false},
+		// 3 nodes in the anchored sequence, expanded twice
+		{"---\na: &a [1, 2]\nb: *a\nc: *a\n", 6, false},
+		{"---\na: &a [1, 2]\nb: *a\nc: *a\n", 5, true},
+		{"---\na: [1, 2]\nb: [1, 2]\n", 1, false},
+	}
+	for _, test := range tests {
+		opts := []Option{}
+		if test.budget != DefaultMaxAliasExpansion {
+			opts = append(opts, WithMaxAliasExpansion(test.budget))
+		}
+		_, err := Unmarshal(test.input, opts...)
+		if test.fails != errors.Is(err, ErrAliasBudgetExceeded) {
+			t.Errorf("invalid result with budget %d: %v", test.budget, err)
+		}
+		var target interface{}
+		err = UnmarshalInto(test.input, &target, opts...)
+		if test.fails != errors.Is(err, ErrAliasBudgetExceeded) {
+			t.Errorf("invalid result into target with budget %d: %v", test.budget, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalAll is like Unmarshal, but instead of stopping at the first
+// document in the input it unmarshals all of them and returns them as a
+// slice, in the same order as they appear in the input; each element is a
+// map or an array (or a scalar) as returned by Unmarshal, and the options
+// (e.g. includes, validation against a schema) apply to each document on its
+// own. For YAML, the documents are separated by "---" (e.g. a set of
+// Kubernetes manifests); for JSON, the input can be a sequence of
+// whitespace-separated values, as in JSON Lines; for NDJSON, each line is a
+// document; TOML only supports a single document per input.
+func UnmarshalAll(value string, opts ...Option) ([]interface{}, error) {
+	o := newOptions(opts...)
+	// read data and detect its format
+	format, content, err := readContent(value, o)
+	if err != nil {
+		return nil, err
+	}
+	if isEmpty(content) {
+		return []interface{}{}, nil
+	}
+	switch format {
+	case FormatJSON:
+		return unmarshalAllJSON(value, prepareJSON(content, o), o)
+	case FormatYAML:
+		return unmarshalAllYAML(value, content, o)
+	case FormatNDJSON:
+		// each line is a document
+		result := []interface{}{}
+		err := eachNDJSONLine(content, o, func(line []byte) error {
+			document, err := decodeContent(FormatJSON, line, value, describeDocument(len(result), value), o)
+			if err == nil {
+				result = append(result, document)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	default:
+		result, err := decodeContent(format, content, value, describeValue(value), o)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{result}, nil
+	}
+}
+
+// unmarshalAllJSON unmarshals a stream of JSON values.
+func unmarshalAllJSON(value string, content []byte, o *options) ([]interface{}, error) {
+	result := []interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	for {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error unmarshalling document %d from JSON: %w", len(result), err)
+		}
+		document, err := decodeContent(FormatJSON, raw, value, describeDocument(len(result), value), o)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, document)
+	}
+	return result, nil
+}
+
+// unmarshalAllYAML unmarshals a stream of YAML documents; each one is encoded
+// again on its own, so that it can be decoded like a whole input.
+func unmarshalAllYAML(value string, content []byte, o *options) ([]interface{}, error) {
+	result := []interface{}{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		node := yaml.Node{}
+		if err := decoder.Decode(&node); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error unmarshalling document %d from YAML: %w", len(result), err)
+		}
+		var raw []byte
+		if len(node.Content) > 0 {
+			var err error
+			if raw, err = yaml.Marshal(&node); err != nil {
+				return nil, fmt.Errorf("error unmarshalling document %d from YAML: %w", len(result), err)
+			}
+		}
+		document, err := decodeContent(FormatYAML, raw, value, describeDocument(len(result), value), o)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, document)
+	}
+	return result, nil
+}
+
+// describeDocument returns a description of the document with the given
+// index in the input value, for use in error messages.
+func describeDocument(index int, value string) string {
+	return fmt.Sprintf("document %d in %s", index, describeValue(value))
+}
@@ -0,0 +1,106 @@
+package rawdata
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalAll(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []interface{}
+	}{
+		{
+			"@./test/manifests.yaml",
+			[]interface{}{
+				map[string]interface{}{"kind": "Service", "name": "frontend"},
+				map[string]interface{}{"kind": "Deployment", "name": "frontend"},
+				[]interface{}{"one", "two"},
+			},
+		},
+		{
+			"json:@./test/events.jsonl",
+			[]interface{}{
+				map[string]interface{}{"event": "start", "id": float64(1)},
+				map[string]interface{}{"event": "stop", "id": float64(2)},
+				[]interface{}{"one", "two"},
+			},
+		},
+		{
+			"@./test/struct.json",
+			[]interface{}{
+				map[string]interface{}{"name": "John", "surname": "Doe", "age": float64(23)},
+			},
+		},
+		{
+			"@./test/struct.toml",
+			[]interface{}{
+				map[string]interface{}{"name": "John", "surname": "Doe", "age": int64(23)},
+			},
+		},
+	}
+	for _, test := range tests {
+		result, err := UnmarshalAll(test.input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", test.input, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("error unmarshalling %q: expected %v, got %v", test.input, test.expected, result)
+		}
+	}
+
+	for _, input := range []string{"@./test/invalid.yaml", "@./test/invalid.json", "{} {"} {
+		if _, err := UnmarshalAll(input); err == nil {
+			t.Errorf("no error unmarshalling invalid input %q", input)
+		}
+	}
+}
+
+func TestUnmarshalAllOptions(t *testing.T) {
+	input := "---\nname: app\ntls: \"@shared/tls.yaml\"\nsize: 3\nat: 2023-01-02T03:04:05Z\n---\n---\n- 1.5\n"
+	result, err := UnmarshalAll(input, WithIncludes(true), WithBaseDir("./test/include"), WithNumberMode(NumberModePreferInt), WithParseTimestamps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling with options: %v", err)
+	}
+	if len(result) != 3 || result[1] != nil || !reflect.DeepEqual(result[2], []interface{}{1.5}) {
+		t.Fatalf("invalid documents: %v", result)
+	}
+	first, ok := result[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("invalid first document: %v", result[0])
+	}
+	if _, ok := first["tls"].(map[string]interface{}); !ok {
+		t.Errorf("include not resolved: %v", first["tls"])
+	}
+	if first["size"] != 3 {
+		t.Errorf("number mode not applied: %T", first["size"])
+	}
+	if _, ok := first["at"].(time.Time); !ok {
+		t.Errorf("timestamp not parsed: %T", first["at"])
+	}
+
+	schema, err := os.ReadFile("./test/person.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{
+		"---\nname: John\nsurname: Doe\n---\nname: Jane\n",
+		`{"name": "John", "surname": "Doe"} {"name": "Jane"}`,
+		"ndjson:{\"name\": \"John\", \"surname\": \"Doe\"}\n{\"name\": \"Jane\"}\n",
+	} {
+		if _, err := UnmarshalAll(input, WithSchema(schema)); err == nil {
+			t.Errorf("no error validating the documents in %q", input)
+		}
+	}
+
+	result, err = UnmarshalAll("ndjson:{\"id\": 1}\n{\"id\": \n{\"id\": 3}\n", WithInvalidLineHandler(func(*DecodeError) error { return nil }))
+	if err != nil || len(result) != 2 {
+		t.Errorf("invalid lines not skipped: %v (error: %v)", result, err)
+	}
+	if _, err := UnmarshalAll("---\nname: John\n---\na: {b: {c: 1}}\n", WithMaxDepth(2)); err == nil || !strings.Contains(err.Error(), "document 1 in inline value") {
+		t.Errorf("invalid error for a document too deep: %v", err)
+	}
+}
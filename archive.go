@@ -0,0 +1,155 @@
+package rawdata
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// archiveSeparator separates the path of an archive from the name of a
+// member in it, in file references such as "@bundle.zip//config/app.yaml".
+const archiveSeparator = "//"
+
+// cutArchiveMember checks whether the value is a reference to a member of an
+// archive, i.e. "@" followed by the path of an archive with a supported
+// extension, the separator and the name of the member; if so, it returns the
+// path of the archive and the name of the member.
+func cutArchiveMember(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, "@") {
+		return "", "", false
+	}
+	archive, member, ok := strings.Cut(strings.TrimPrefix(value, "@"), archiveSeparator)
+	if !ok || archiveType(archive) == "" {
+		return "", "", false
+	}
+	return archive, member, true
+}
+
+// archiveType returns the type of the archive with the given path, from its
+// extension: "zip", "tar" or "tar.gz" (also as ".tgz"); it returns "" if the
+// extension is not that of a supported archive.
+func archiveType(archive string) string {
+	archive = strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(archive, ".zip"):
+		return "zip"
+	case strings.HasSuffix(archive, ".tar"):
+		return "tar"
+	case strings.HasSuffix(archive, ".tar.gz") || strings.HasSuffix(archive, ".tgz"):
+		return "tar.gz"
+	default:
+		return ""
+	}
+}
+
+// loadArchiveMember reads the given member of an archive; the format is
+// detected from the extension of the member, as for a file. The maximum file
+// size applies to the member, not to the archive.
+func loadArchiveMember(archive string, member string, forced Format, o *options) (Format, []byte, error) {
+	member = strings.TrimPrefix(path.Clean("/"+member), "/")
+	file, info, err := openFile(archive, o)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	defer file.Close()
+	var content []byte
+	if archiveType(archive) == "zip" {
+		content, err = readZipMember(file, info.Size(), archive, member, o)
+	} else {
+		content, err = readTarMember(file, archive, member, o)
+	}
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	if content, err = decodeText(content); err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error reading '%s' in archive '%s': %w", member, archive, err)
+	}
+	format, err := fileFormat(member, content, forced, o)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	return format, content, nil
+}
+
+// readZipMember reads the given member of a zip archive.
+func readZipMember(file fs.File, size int64, archive string, member string, o *options) ([]byte, error) {
+	reader, ok := file.(io.ReaderAt)
+	if !ok {
+		// zip archives need random access, so the archive must be read into
+		// memory if the file does not support it
+		data, err := readAll(contextReader{o.context(), file}, o.maxFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("error reading archive '%s': %w", archive, err)
+		}
+		reader, size = bytes.NewReader(data), int64(len(data))
+	}
+	zr, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archive '%s': %w", archive, err)
+	}
+	entry, err := zr.Open(member)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("'%s' does not exist in archive '%s': %w", member, archive, ErrFileNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading '%s' in archive '%s': %w", member, archive, err)
+	}
+	defer entry.Close()
+	if info, err := entry.Stat(); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("'%s' in archive '%s' %w", member, archive, ErrIsDirectory)
+	}
+	content, err := readAll(contextReader{o.context(), entry}, o.maxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("error reading '%s' in archive '%s': %w", member, archive, err)
+	}
+	return content, nil
+}
+
+// readTarMember reads the given member of a tar archive, decompressing the
+// archive on the fly if it is gzipped; the archive is scanned sequentially, so
+// it is never held in memory as a whole.
+func readTarMember(file fs.File, archive string, member string, o *options) ([]byte, error) {
+	var reader io.Reader = contextReader{o.context(), file}
+	if archiveType(archive) == "tar.gz" {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing archive '%s': %w", archive, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("'%s' does not exist in archive '%s': %w", member, archive, ErrFileNotFound)
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading archive '%s': %w", archive, err)
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if strings.HasPrefix(name, member+"/") {
+			// archives need not have entries for directories
+			return nil, fmt.Errorf("'%s' in archive '%s' %w", member, archive, ErrIsDirectory)
+		} else if name != member {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			return nil, fmt.Errorf("'%s' in archive '%s' %w", member, archive, ErrIsDirectory)
+		case tar.TypeReg:
+			content, err := readAll(tr, o.maxFileSize)
+			if err != nil {
+				return nil, fmt.Errorf("error reading '%s' in archive '%s': %w", member, archive, err)
+			}
+			return content, nil
+		default:
+			return nil, fmt.Errorf("'%s' in archive '%s' is not a regular file", member, archive)
+		}
+	}
+}
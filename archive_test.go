@@ -0,0 +1,91 @@
+package rawdata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalZipMember(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"@./test/bundle.zip//config/app.yaml":      FormatYAML,
+		"@./test/bundle.zip//config/app.json":      FormatJSON,
+		"@./test/bundle.zip///config/./app.json":   FormatJSON,
+		"json:@./test/bundle.zip//config/app.conf": FormatJSON,
+	} {
+		result, format, err := UnmarshalWithFormat(input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+		if object := result.(map[string]interface{}); object["name"] != "myapp" {
+			t.Errorf("invalid result for %q: %v", input, result)
+		}
+	}
+	type config struct {
+		Name string `json:"name" yaml:"name"`
+		Port int    `json:"port" yaml:"port"`
+	}
+	target := config{}
+	if err := UnmarshalInto("@./test/bundle.zip//config/app.yaml", &target, WithBaseDir(".")); err != nil {
+		t.Fatalf("error unmarshalling member into target: %v", err)
+	}
+	if target.Port != 8080 {
+		t.Errorf("invalid target: %+v", target)
+	}
+}
+
+func TestUnmarshalInvalidZipMember(t *testing.T) {
+	for input, expected := range map[string]error{
+		"@./test/bundle.zip//config/missing.yaml": ErrFileNotFound,
+		"@./test/bundle.zip//config":              ErrIsDirectory,
+		"@./test/bundle.zip//config/app.conf":     ErrUnsupportedFormat,
+		"@./test/missing.zip//config/app.yaml":    ErrFileNotFound,
+	} {
+		if _, err := Unmarshal(input); !errors.Is(err, expected) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+		}
+	}
+	if _, err := Unmarshal("@./test/bundle.zip//config/app.yaml", WithMaxFileSize(8)); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("invalid error for oversized member: %v", err)
+	}
+	if _, err := Unmarshal("@./test/bundle.zip//config/app.yaml", WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("invalid error with file access disabled: %v", err)
+	}
+}
+
+func TestUnmarshalTarMember(t *testing.T) {
+	for _, archive := range []string{"./test/bundle.tar", "./test/bundle.tar.gz"} {
+		for member, expected := range map[string]Format{
+			"config/app.yaml":     FormatYAML,
+			"config/app.json":     FormatJSON,
+			"layers/etc/app.toml": FormatTOML,
+		} {
+			input := "@" + archive + "//" + member
+			result, format, err := UnmarshalWithFormat(input)
+			if err != nil {
+				t.Fatalf("error unmarshalling %q: %v", input, err)
+			}
+			if format != expected {
+				t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+			}
+			if object := result.(map[string]interface{}); object["name"] != "myapp" {
+				t.Errorf("invalid result for %q: %v", input, result)
+			}
+		}
+		for member, expected := range map[string]error{
+			"config/missing.yaml": ErrFileNotFound,
+			"config":              ErrIsDirectory,
+			"layers/etc":          ErrIsDirectory,
+		} {
+			input := "@" + archive + "//" + member
+			if _, err := Unmarshal(input); !errors.Is(err, expected) {
+				t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+			}
+		}
+		if _, err := Unmarshal("@" + archive + "//config/link.yaml"); err == nil {
+			t.Errorf("no error reading link in %q", archive)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package rawdata
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores the contents of files read via "@" references, so that files
+// that have not changed since they were last read need not be read again (see
+// WithCache); entries are keyed by the absolute path of the file and are only
+// valid for the modification time they were stored with. Implementations must
+// be safe for concurrent use, and must not modify the contents.
+type Cache interface {
+	// Load returns the contents of the file with the given path, if they are
+	// in the cache and were stored for the given modification time.
+	Load(path string, modTime time.Time) ([]byte, bool)
+	// Store puts the contents of the file with the given path in the cache,
+	// along with its modification time.
+	Store(path string, modTime time.Time, content []byte)
+	// Clear removes all the entries from the cache.
+	Clear()
+}
+
+// MemoryCache is an in-memory Cache; the zero value is ready to use.
+type MemoryCache struct {
+	lock    sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is the contents of a file, along with its modification time.
+type cacheEntry struct {
+	modTime time.Time
+	content []byte
+}
+
+// NewMemoryCache returns a new, empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{}
+}
+
+// Load implements Cache.
+func (c *MemoryCache) Load(path string, modTime time.Time) ([]byte, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, ok := c.entries[path]
+	if !ok || !entry.modTime.Equal(modTime) {
+		return nil, false
+	}
+	return entry.content, true
+}
+
+// Store implements Cache.
+func (c *MemoryCache) Store(path string, modTime time.Time, content []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]cacheEntry{}
+	}
+	c.entries[path] = cacheEntry{modTime: modTime, content: content}
+}
+
+// Clear implements Cache.
+func (c *MemoryCache) Clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = nil
+}
+
+// Len returns the number of entries in the cache.
+func (c *MemoryCache) Len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.entries)
+}
+
+// readFileCached is like readFile, but it looks the file up in the cache
+// first, if one is set, and stores it there after reading it.
+func readFileCached(filename string, o *options) ([]byte, error) {
+	if o.cache == nil {
+		return readFile(filename, o)
+	}
+	if err := checkAllowedRoot(filename, o); err != nil {
+		return nil, err
+	}
+	if err := checkSymlink(filename, o); err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(o.filesystem(), filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("file '%s' does not exist: %w", filename, ErrFileNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("error accessing file '%s': %w", filename, err)
+	}
+	key := filename
+	if o.fsys == nil {
+		if key, err = filepath.Abs(filename); err != nil {
+			return nil, fmt.Errorf("error accessing file '%s': %w", filename, err)
+		}
+	}
+	if content, ok := o.cache.Load(key, info.ModTime()); ok {
+		// the contents may have been cached with a higher limit
+		if o.maxFileSize > 0 && int64(len(content)) > o.maxFileSize {
+			return nil, fmt.Errorf("file '%s' is %d bytes, limit is %d: %w", filename, len(content), o.maxFileSize, ErrFileTooLarge)
+		}
+		return content, nil
+	}
+	content, err := readFile(filename, o)
+	if err != nil {
+		return nil, err
+	}
+	o.cache.Store(key, info.ModTime(), content)
+	return content, nil
+}
@@ -0,0 +1,72 @@
+package rawdata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithCache(t *testing.T) {
+	cache := NewMemoryCache()
+	filename := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(filename, []byte(`{"name": "John"}`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	os.Chtimes(filename, modTime, modTime)
+
+	result, err := Unmarshal("@"+filename, WithCache(cache))
+	if err != nil || !reflect.DeepEqual(result, map[string]interface{}{"name": "John"}) {
+		t.Fatalf("invalid result: %v (error: %v)", result, err)
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("file not cached: %d entries", cache.Len())
+	}
+
+	// the cached contents are used as long as the modification time is
+	// the same, even if the file changed
+	if err := os.WriteFile(filename, []byte(`{"name": "Jane"}`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	os.Chtimes(filename, modTime, modTime)
+	result, _ = Unmarshal("@"+filename, WithCache(cache))
+	if !reflect.DeepEqual(result, map[string]interface{}{"name": "John"}) {
+		t.Errorf("cached contents not used: %v", result)
+	}
+
+	// a new modification time invalidates the entry
+	os.Chtimes(filename, time.Now(), time.Now())
+	result, _ = Unmarshal("@"+filename, WithCache(cache))
+	if !reflect.DeepEqual(result, map[string]interface{}{"name": "Jane"}) {
+		t.Errorf("stale contents used: %v", result)
+	}
+
+	// inline values are never cached
+	if _, err := Unmarshal(`{"name": "John"}`, WithCache(cache)); err != nil || cache.Len() != 1 {
+		t.Errorf("inline value cached: %d entries (error: %v)", cache.Len(), err)
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("cache not cleared: %d entries", cache.Len())
+	}
+}
+
+func TestWithCacheConcurrently(t *testing.T) {
+	cache := &MemoryCache{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Unmarshal("@./test/struct.yaml", WithCache(cache)); err != nil {
+				t.Errorf("error unmarshalling: %v", err)
+			}
+			cache.Clear()
+		}()
+	}
+	wg.Wait()
+}
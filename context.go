@@ -0,0 +1,85 @@
+package rawdata
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// UnmarshalContext is like Unmarshal, but the given context is used to fetch
+// remote documents and is checked while reading files and standard input, so
+// that long loads can be cancelled or time out; in that case the returned
+// error wraps the context error (e.g. context.DeadlineExceeded). Since a read
+// from a file on a hung filesystem (e.g. an unresponsive NFS mount) can block
+// indefinitely, files and archives are opened and read in a goroutine, and
+// the call returns as soon as the context is done even if the read has not;
+// the goroutine is then left to finish, and to close the file, whenever the
+// read does. This costs a goroutine and a channel per file read, and nothing
+// for contexts that can never be done (e.g. context.Background()).
+func UnmarshalContext(ctx context.Context, value string, opts ...Option) (interface{}, error) {
+	return Unmarshal(value, append(opts[:len(opts):len(opts)], withContext(ctx))...)
+}
+
+// UnmarshalIntoContext is like UnmarshalInto, but it honours the given
+// context as described in UnmarshalContext.
+func UnmarshalIntoContext(ctx context.Context, value string, target interface{}, opts ...Option) error {
+	return UnmarshalInto(value, target, append(opts[:len(opts):len(opts)], withContext(ctx))...)
+}
+
+// UnmarshalMergedContext is like UnmarshalMerged, but it honours the given
+// context as described in UnmarshalContext, and it accepts options; with
+// WithConcurrency, the values are loaded concurrently, the first error
+// cancelling the loading of the rest.
+func UnmarshalMergedContext(ctx context.Context, values []string, opts ...Option) (interface{}, error) {
+	return unmarshalMerged(values, newOptions(append(opts[:len(opts):len(opts)], withContext(ctx))...))
+}
+
+// withContext sets the context of the call.
+func withContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// contextReader is an io.Reader that stops reading as soon as its context
+// is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read implements io.Reader, returning the context error once the context
+// is done.
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// interruptible runs the given function, which reads the named file and may
+// block indefinitely, so that the caller can give up on it as soon as the
+// context of the call is done (see UnmarshalContext).
+func interruptible(filename string, o *options, read func() (Format, []byte, error)) (Format, []byte, error) {
+	ctx := o.context()
+	if ctx.Done() == nil {
+		// the context can never be done
+		return read()
+	}
+	type result struct {
+		format  Format
+		content []byte
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		format, content, err := read()
+		done <- result{format, content, err}
+	}()
+	select {
+	case r := <-done:
+		return r.format, r.content, r.err
+	case <-ctx.Done():
+		return FormatUnknown, nil, fmt.Errorf("error reading file '%s': %w", filename, ctx.Err())
+	}
+}
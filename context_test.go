@@ -0,0 +1,116 @@
+package rawdata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestUnmarshalContext(t *testing.T) {
+	result, err := UnmarshalContext(context.Background(), "@./test/struct.json")
+	if err != nil {
+		t.Fatalf("error unmarshalling with context: %v", err)
+	}
+	if result.(map[string]interface{})["name"] != "John" {
+		t.Errorf("invalid result: %v", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := UnmarshalContext(ctx, "@./test/struct.json"); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error with cancelled context: %v", err)
+	}
+	target := &s{}
+	if err := UnmarshalIntoContext(ctx, `{"name": "John"}`, target); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error with cancelled context: %v", err)
+	}
+}
+
+// cancellingReader cancels its context after the first read.
+type cancellingReader struct {
+	cancel context.CancelFunc
+	data   *strings.Reader
+}
+
+func (r cancellingReader) Read(p []byte) (int, error) {
+	defer r.cancel()
+	return r.data.Read(p[:1])
+}
+
+func TestUnmarshalContextCancelledMidRead(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = cancellingReader{cancel: cancel, data: strings.NewReader(`{"name": "John"}`)}
+	if _, err := UnmarshalContext(ctx, "@-"); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error cancelling mid-read: %v", err)
+	}
+}
+
+func TestUnmarshalContextFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := UnmarshalContext(ctx, server.URL+"/slow.json"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("invalid error fetching slow URL: %v", err)
+	}
+}
+
+// hungFS is a filesystem whose files cannot be opened until it is released,
+// like a file on an unresponsive network mount.
+type hungFS struct {
+	fstest.MapFS
+	release chan struct{}
+}
+
+// Open blocks until the filesystem is released.
+func (f hungFS) Open(name string) (fs.File, error) {
+	<-f.release
+	return f.MapFS.Open(name)
+}
+
+func TestUnmarshalContextHungFile(t *testing.T) {
+	fsys := hungFS{
+		MapFS: fstest.MapFS{
+			"app.json":   {Data: []byte(`{"name": "John"}`)},
+			"bundle.zip": {Data: []byte("PK")},
+		},
+		release: make(chan struct{}),
+	}
+	defer close(fsys.release)
+	for _, input := range []string{"@app.json", "@bundle.zip//app.json"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		var target map[string]interface{}
+		err := UnmarshalIntoContext(ctx, input, &target, WithFS(fsys))
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("invalid error for %q: %v", input, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("read of %q not interrupted: took %v", input, elapsed)
+		}
+	}
+
+	// once released, files are read as usual
+	release := hungFS{MapFS: fsys.MapFS, release: make(chan struct{})}
+	close(release.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if result, err := UnmarshalContext(ctx, "@app.json", WithFS(release)); err != nil || result.(map[string]interface{})["name"] != "John" {
+		t.Errorf("invalid result: %v (%v)", result, err)
+	}
+}
@@ -0,0 +1,50 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// unmarshalCSV unmarshals CSV data into an array with one element per
+// record: if the first record is a header (see WithCSVHeader), each element
+// is an object keyed by the column names, otherwise it is an array of
+// fields. All fields are unmarshalled as strings.
+func unmarshalCSV(content []byte, o *options) (interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = o.csvDelimiter
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, newDecodeError(FormatCSV, content, err)
+	}
+	result := []interface{}{}
+	if !o.csvHeader {
+		for _, record := range records {
+			fields := make([]interface{}, len(record))
+			for i, field := range record {
+				fields[i] = field
+			}
+			result = append(result, fields)
+		}
+		return result, nil
+	}
+	if len(records) == 0 {
+		return result, nil
+	}
+	header := records[0]
+	for _, record := range records[1:] {
+		if o.orderedMaps {
+			object := NewOrderedMap()
+			for i, field := range record {
+				object.Set(header[i], field)
+			}
+			result = append(result, object)
+		} else {
+			object := make(map[string]interface{}, len(record))
+			for i, field := range record {
+				object[header[i]] = field
+			}
+			result = append(result, object)
+		}
+	}
+	return result, nil
+}
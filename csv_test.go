@@ -0,0 +1,57 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalCSV(t *testing.T) {
+	result, err := Unmarshal("@./test/people.csv")
+	if err != nil {
+		t.Fatalf("error unmarshalling CSV: %v", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"name": "John", "surname": "Doe", "age": "23"},
+		map[string]interface{}{"name": "Smith, Jr.", "surname": "Jane", "age": "42"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+
+	result, err = Unmarshal("csv:a;b\n1;2", WithCSVDelimiter(';'), WithCSVHeader(false))
+	if err != nil {
+		t.Fatalf("error unmarshalling CSV without header: %v", err)
+	}
+	expected = []interface{}{
+		[]interface{}{"a", "b"},
+		[]interface{}{"1", "2"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result without header: %v", result)
+	}
+
+	result, err = Unmarshal("@./test/people.csv", WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling ordered CSV: %v", err)
+	}
+	if keys := result.([]interface{})[0].(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"name", "surname", "age"}) {
+		t.Errorf("invalid key order: %v", keys)
+	}
+
+	if _, err := Unmarshal("csv:a,b\n1,2,3"); err == nil {
+		t.Error("no error unmarshalling CSV with inconsistent records")
+	}
+}
+
+func TestUnmarshalCSVInto(t *testing.T) {
+	target := []struct {
+		Name    string `json:"name"`
+		Surname string `json:"surname"`
+	}{}
+	if err := UnmarshalInto("@./test/people.csv", &target); err != nil {
+		t.Fatalf("error unmarshalling CSV into slice: %v", err)
+	}
+	if len(target) != 2 || target[1].Name != "Smith, Jr." || target[1].Surname != "Jane" {
+		t.Errorf("invalid target: %+v", target)
+	}
+}
@@ -0,0 +1,72 @@
+package rawdata
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// isDataURI returns whether the value is a data URI; to avoid mistaking
+// inline YAML such as "data: 42" for one, the scheme must be followed
+// directly by the media type or the comma.
+func isDataURI(value string) bool {
+	if len(value) <= len("data:") || !strings.EqualFold(value[:len("data:")], "data:") {
+		return false
+	}
+	switch value[len("data:")] {
+	case ' ', '\t', '\r', '\n':
+		return false
+	}
+	return true
+}
+
+// parseDataURI decodes the payload of a data URI (RFC 2397), i.e.
+// "data:[<media type>][;base64],<data>", where the data is either base64
+// (standard or URL-safe, with or without padding) or percent-encoded; the
+// format is taken from the media type, if it is a supported one.
+func parseDataURI(value string, o *options) (Format, []byte, error) {
+	header, data, ok := strings.Cut(value[len("data:"):], ",")
+	if !ok {
+		return FormatUnknown, nil, fmt.Errorf("no comma before the data: %w", ErrInvalidDataURI)
+	}
+	encoded := false
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		header = header[:len(header)-len(";base64")]
+		encoded = true
+	}
+	format := FormatUnknown
+	if header != "" && !strings.HasPrefix(header, ";") {
+		if _, _, err := mime.ParseMediaType(header); err != nil {
+			return FormatUnknown, nil, fmt.Errorf("invalid media type '%s': %w", header, ErrInvalidDataURI)
+		}
+		format = formatFromContentType(header)
+	}
+	var content []byte
+	if encoded {
+		var err error
+		if content, err = decodeBase64(data); err != nil {
+			return FormatUnknown, nil, fmt.Errorf("invalid base64 data: %v: %w", err, ErrInvalidDataURI)
+		}
+	} else {
+		unescaped, err := url.PathUnescape(data)
+		if err != nil {
+			return FormatUnknown, nil, fmt.Errorf("invalid percent-encoded data: %v: %w", err, ErrInvalidDataURI)
+		}
+		content = []byte(unescaped)
+	}
+	if o.maxFileSize > 0 && int64(len(content)) > o.maxFileSize {
+		return FormatUnknown, nil, fmt.Errorf("data URI payload exceeds the limit of %d bytes: %w", o.maxFileSize, ErrFileTooLarge)
+	}
+	return format, content, nil
+}
+
+// decodeBase64 decodes base64 data in any of the common variants.
+func decodeBase64(data string) ([]byte, error) {
+	data = strings.TrimRight(data, "=")
+	if strings.ContainsAny(data, "-_") {
+		return base64.RawURLEncoding.DecodeString(data)
+	}
+	return base64.RawStdEncoding.DecodeString(data)
+}
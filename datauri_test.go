@@ -0,0 +1,69 @@
+package rawdata
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalDataURI(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"name": "John", "age": 23}`))
+	tests := []struct {
+		input  string
+		format Format
+	}{
+		{"data:application/json;base64," + encoded, FormatJSON},
+		{"data:application/json;charset=utf-8;base64," + encoded, FormatJSON},
+		{"data:;base64," + encoded, FormatJSON},
+		{"data:application/yaml,name:%20John%0Aage:%2023", FormatYAML},
+		{"data:application/toml,name%20=%20%22John%22%0Aage%20=%2023", FormatTOML},
+		{"DATA:text/plain,%7B%22name%22:%22John%22,%22age%22:23%7D", FormatJSON},
+	}
+	for _, test := range tests {
+		result, format, err := UnmarshalWithFormat(test.input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", test.input, err)
+		}
+		if format != test.format {
+			t.Errorf("invalid format for %q: expected %v, got %v", test.input, test.format, format)
+		}
+		if name := result.(map[string]interface{})["name"]; name != "John" {
+			t.Errorf("invalid result for %q: %v", test.input, result)
+		}
+	}
+}
+
+func TestUnmarshalDataURIFormatFromMediaType(t *testing.T) {
+	// the media type wins over sniffing, which would detect JSON
+	result, format, err := UnmarshalWithFormat("data:application/yaml,%7Ba:%201%7D")
+	if err != nil {
+		t.Fatalf("error unmarshalling data URI: %v", err)
+	}
+	if format != FormatYAML {
+		t.Errorf("invalid format: expected yaml, got %v", format)
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"a": 1}) {
+		t.Errorf("invalid result: %v", result)
+	}
+}
+
+func TestUnmarshalInvalidDataURI(t *testing.T) {
+	for _, input := range []string{
+		"data:application/json;base64",
+		"data:application/json;base64,not base64!",
+		"data:application/json,%zz",
+		"data:application//json,{}",
+	} {
+		if _, err := Unmarshal(input); !errors.Is(err, ErrInvalidDataURI) {
+			t.Errorf("invalid error for %q: %v", input, err)
+		}
+	}
+	if _, err := Unmarshal("data:application/json,[1,2,3]", WithMaxFileSize(4)); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("invalid error for oversized data URI: %v", err)
+	}
+	// inline YAML is not mistaken for a data URI
+	if _, err := Unmarshal("---\ndata: 42"); err != nil {
+		t.Errorf("error unmarshalling inline YAML: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package rawdata
+
+// Decoder unmarshals values with a fixed set of options, so that they need
+// not be passed to every call; any state they refer to, such as the cache,
+// the filesystem or the HTTP client, is shared by all the calls. A Decoder is
+// safe for concurrent use by multiple goroutines, as long as that state is.
+type Decoder struct {
+	o options
+}
+
+// NewDecoder returns a Decoder using the given options.
+func NewDecoder(opts ...Option) *Decoder {
+	return &Decoder{o: *newOptions(opts...)}
+}
+
+// options returns a copy of the options of the decoder, so that calls cannot
+// affect one another.
+func (d *Decoder) options() *options {
+	o := d.o
+	return &o
+}
+
+// Unmarshal is like the package-level Unmarshal, with the options of the
+// decoder.
+func (d *Decoder) Unmarshal(value string) (interface{}, error) {
+	result, _, err := unmarshalWithFormat(value, d.options())
+	return result, err
+}
+
+// UnmarshalWithFormat is like the package-level UnmarshalWithFormat, with the
+// options of the decoder.
+func (d *Decoder) UnmarshalWithFormat(value string) (interface{}, Format, error) {
+	return unmarshalWithFormat(value, d.options())
+}
+
+// UnmarshalInto is like the package-level UnmarshalInto, with the options of
+// the decoder.
+func (d *Decoder) UnmarshalInto(value string, target interface{}) error {
+	return unmarshalInto(value, target, d.options())
+}
+
+// UnmarshalMerged is like the package-level UnmarshalMerged, with the
+// options of the decoder; with WithConcurrency, the values are loaded
+// concurrently.
+func (d *Decoder) UnmarshalMerged(values ...string) (interface{}, error) {
+	return unmarshalMerged(values, d.options())
+}
+
+// ReadContent is like the package-level ReadContent, with the options of the
+// decoder.
+func (d *Decoder) ReadContent(value string) (Format, []byte, error) {
+	return readContent(value, d.options())
+}
@@ -0,0 +1,85 @@
+package rawdata
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	cache := NewMemoryCache()
+	decoder := NewDecoder(WithCache(cache), WithOrderedMaps(true), WithAllowFileAccess(true))
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			result, err := decoder.Unmarshal("@./test/struct.json")
+			if err == nil {
+				if _, ok := result.(*OrderedMap); !ok {
+					err = errors.New("options not applied")
+				}
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			target := &s{}
+			err := decoder.UnmarshalInto("@./test/struct.yaml", target)
+			if err == nil && target.Name != "John" {
+				err = errors.New("invalid target")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("error unmarshalling with decoder: %v", err)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("cache not shared: %d entries", cache.Len())
+	}
+	format, content, err := decoder.ReadContent("[1, 2, 3]")
+	if err != nil || format != FormatJSON || string(content) != "[1, 2, 3]" {
+		t.Errorf("invalid content: %v %q (error: %v)", format, content, err)
+	}
+	if _, format, err := decoder.UnmarshalWithFormat("---\na: 1"); err != nil || format != FormatYAML {
+		t.Errorf("invalid format: %v (error: %v)", format, err)
+	}
+	if _, err := NewDecoder(WithAllowFileAccess(false)).Unmarshal("@./test/struct.json"); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("invalid error with file access disabled: %v", err)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"name": "John"}`))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: tokenTransport{}}
+	if _, err := Unmarshal(server.URL); err == nil {
+		t.Fatal("no error without the custom client")
+	}
+	if _, err := NewDecoder(WithHTTPClient(client)).Unmarshal(server.URL); err != nil {
+		t.Fatalf("error unmarshalling with the custom client: %v", err)
+	}
+}
+
+// tokenTransport adds an authentication header to every request.
+type tokenTransport struct{}
+
+func (tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("X-Token", "secret")
+	return http.DefaultTransport.RoundTrip(request)
+}
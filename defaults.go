@@ -0,0 +1,54 @@
+package rawdata
+
+import (
+	"fmt"
+)
+
+// applyDefaults merges the data on top of the defaults set via WithDefaults
+// and returns the result, encoded in the format it must be decoded from into
+// the target: the format of the data itself for JSON, YAML and TOML, so that
+// the same struct tags apply to the defaults and to the data, and JSON for the
+// formats that are decoded into targets via their JSON representation.
+func applyDefaults(format Format, content []byte, o *options) (Format, []byte, error) {
+	encoding := format
+	switch format {
+	case FormatJSON, FormatYAML, FormatTOML:
+	case FormatCSV, FormatProperties, FormatNDJSON:
+		encoding = FormatJSON
+	case FormatUnknown:
+		if !isEmpty(content) {
+			return FormatUnknown, nil, fmt.Errorf("defaults not supported for unknown data: %w", ErrUnsupportedFormat)
+		}
+		// empty data (see WithAllowEmpty), the defaults are all there is
+		encoding = FormatJSON
+	default:
+		return FormatUnknown, nil, fmt.Errorf("defaults not supported for %s data: %w", formatLabel(format), ErrUnsupportedFormat)
+	}
+	// merging needs plain maps, and JSON numbers must survive the round trip
+	generic := *o
+	generic.orderedMaps = false
+	generic.numberMode = NumberModeDefault
+	generic.useNumber = format == FormatJSON
+	data, err := decode(format, content, &generic)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	encoded, err := Marshal(o.defaults, encoding)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error encoding defaults: %w", err)
+	}
+	defaults, err := decode(encoding, encoded, &generic)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error encoding defaults: %w", err)
+	}
+	merged := defaults
+	if data != nil {
+		if merged, err = Merge(defaults, data); err != nil {
+			return FormatUnknown, nil, fmt.Errorf("error applying defaults: %w", err)
+		}
+	}
+	if content, err = Marshal(merged, encoding); err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error applying defaults: %w", err)
+	}
+	return encoding, content, nil
+}
@@ -0,0 +1,74 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type defaultsServer struct {
+	Host    string   `json:"host" yaml:"host" toml:"host"`
+	Port    int      `json:"port" yaml:"port" toml:"port"`
+	Debug   bool     `json:"debug" yaml:"debug" toml:"debug"`
+	Tags    []string `json:"tags" yaml:"tags" toml:"tags"`
+	Limits  *defaultsLimits
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty" toml:"comment,omitempty"`
+}
+
+type defaultsLimits struct {
+	Connections int `json:"connections" yaml:"connections" toml:"connections"`
+	Timeout     int `json:"timeout" yaml:"timeout" toml:"timeout"`
+}
+
+func TestWithDefaults(t *testing.T) {
+	defaults := defaultsServer{
+		Host:   "localhost",
+		Port:   8080,
+		Debug:  true,
+		Tags:   []string{"a", "b"},
+		Limits: &defaultsLimits{Connections: 100, Timeout: 30},
+	}
+	expected := defaultsServer{
+		Host:   "example.com",
+		Port:   8080,
+		Debug:  false,
+		Tags:   []string{"c"},
+		Limits: &defaultsLimits{Connections: 100, Timeout: 5},
+	}
+	for _, input := range []string{
+		`{"host": "example.com", "debug": false, "tags": ["c"], "Limits": {"timeout": 5}}`,
+		"---\nhost: example.com\ndebug: false\ntags: [c]\nlimits:\n  timeout: 5\n",
+		"toml:host = 'example.com'\ndebug = false\ntags = ['c']\n[Limits]\ntimeout = 5\n",
+	} {
+		target := defaultsServer{}
+		if err := UnmarshalInto(input, &target, WithDefaults(defaults), WithStrict(true)); err != nil {
+			t.Fatalf("error unmarshalling %q with defaults: %v", input, err)
+		}
+		if !reflect.DeepEqual(target, expected) {
+			t.Errorf("invalid result for %q: %+v (limits %+v)", input, target, target.Limits)
+		}
+	}
+	if defaults.Host != "localhost" || defaults.Limits.Timeout != 30 {
+		t.Errorf("defaults modified: %+v", defaults)
+	}
+	target := defaultsServer{}
+	if err := UnmarshalInto("", &target, WithDefaults(defaults), WithAllowEmpty(true)); err != nil {
+		t.Fatalf("error unmarshalling empty data with defaults: %v", err)
+	}
+	if !reflect.DeepEqual(target, defaults) {
+		t.Errorf("invalid result for empty data: %+v", target)
+	}
+	object := map[string]interface{}{}
+	if err := UnmarshalInto(`{"b": 2}`, &object, WithDefaults(map[string]interface{}{"a": 1, "b": 1})); err != nil {
+		t.Fatalf("error unmarshalling into map with defaults: %v", err)
+	}
+	if !reflect.DeepEqual(object, map[string]interface{}{"a": 1.0, "b": 2.0}) {
+		t.Errorf("invalid result for map: %v", object)
+	}
+	if err := UnmarshalInto(`["a"]`, &object, WithDefaults(map[string]interface{}{"a": 1})); err == nil {
+		t.Error("no error merging array into object defaults")
+	}
+	if err := UnmarshalInto("<a>1</a>", &object, WithDefaults(defaults)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("invalid error for XML data: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package rawdata
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkJSONDepth returns an error if the JSON content is nested more deeply
+// than allowed; it scans the raw data, so it can be used before decoding.
+func checkJSONDepth(content []byte, o *options) error {
+	if o.maxDepth <= 0 {
+		return nil
+	}
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '"':
+			i = skipJSONString(content, i)
+		case '{', '[':
+			if depth++; depth > o.maxDepth {
+				return fmt.Errorf("JSON data nests deeper than %d levels: %w", o.maxDepth, ErrMaxDepthExceeded)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
+
+// checkYAMLDepth returns an error if the YAML document is nested more deeply
+// than allowed; aliases are followed, so that they count as the nodes they
+// refer to.
+func checkYAMLDepth(document *yaml.Node, o *options) error {
+	if o.maxDepth <= 0 {
+		return nil
+	}
+	for _, node := range document.Content {
+		if yamlDepthExceeds(node, 0, o.maxDepth) {
+			return fmt.Errorf("YAML data nests deeper than %d levels: %w", o.maxDepth, ErrMaxDepthExceeded)
+		}
+	}
+	return nil
+}
+
+// yamlDepthExceeds returns whether the node, found at the given depth, has
+// more than max levels of nesting.
+func yamlDepthExceeds(node *yaml.Node, depth int, max int) bool {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return node.Alias != nil && yamlDepthExceeds(node.Alias, depth, max)
+	case yaml.MappingNode, yaml.SequenceNode:
+		if depth++; depth > max {
+			return true
+		}
+		for _, child := range node.Content {
+			if yamlDepthExceeds(child, depth, max) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// limitDepth returns an error if the decoded value is nested more deeply
+// than allowed; it is meant to wrap the results of decoding functions.
+func (o *options) limitDepth(v interface{}, err error) (interface{}, error) {
+	if err != nil || o.maxDepth <= 0 {
+		return v, err
+	}
+	if valueDepth(v) > o.maxDepth {
+		return nil, fmt.Errorf("data nests deeper than %d levels: %w", o.maxDepth, ErrMaxDepthExceeded)
+	}
+	return v, nil
+}
+
+// valueDepth returns the nesting depth of a decoded value.
+func valueDepth(v interface{}) int {
+	max := 0
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, value := range v {
+			if depth := valueDepth(value); depth > max {
+				max = depth
+			}
+		}
+	case *OrderedMap:
+		v.Range(func(key string, value interface{}) bool {
+			if depth := valueDepth(value); depth > max {
+				max = depth
+			}
+			return true
+		})
+	case []interface{}:
+		for _, value := range v {
+			if depth := valueDepth(value); depth > max {
+				max = depth
+			}
+		}
+	default:
+		return 0
+	}
+	return max + 1
+}
@@ -0,0 +1,44 @@
+package rawdata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxDepth(t *testing.T) {
+	nested := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+	deepYAML := "---\na:\n  b:\n    c:\n      - d\n"
+	tests := []struct {
+		input string
+		depth int
+		fails bool
+	}{
+		{`{"a": [1, {"b": "[[[{{{"}]}`, 3, false},
+		{`{"a": [1, {"b": "[[[{{{"}]}`, 2, true},
+		{nested, 100, false},
+		{nested, 99, true},
+		{deepYAML, 4, false},
+		{deepYAML, 3, true},
+		{"---\nbase: &base [[1]]\nother: *base\n", 3, false},
+		{"---\nbase: &base [[1]]\nother: [*base]\n", 3, true},
+		{"toml:[a.b.c]\nd = 1", 4, false},
+		{"toml:[a.b.c]\nd = 1", 3, true},
+		{"<a><b><c>1</c></b></a>", 2, true},
+		{`"scalar"`, 1, false},
+	}
+	for _, test := range tests {
+		_, err := Unmarshal(test.input, WithMaxDepth(test.depth))
+		if test.fails != errors.Is(err, ErrMaxDepthExceeded) {
+			t.Errorf("invalid result for %q with maximum depth %d: %v", test.input, test.depth, err)
+		}
+		var target interface{}
+		err = UnmarshalInto(test.input, &target, WithMaxDepth(test.depth))
+		if test.fails != errors.Is(err, ErrMaxDepthExceeded) {
+			t.Errorf("invalid result for %q into target with maximum depth %d: %v", test.input, test.depth, err)
+		}
+	}
+	if _, err := Unmarshal(nested); err != nil {
+		t.Errorf("error unmarshalling nested data without limit: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DetectFormat returns the format of the given value without unmarshalling
+// it: an explicit format prefix (e.g. "json:") always wins; for file
+// references and URLs the format is detected from the extension, without
+// reading the file (which may therefore not even exist), and only if the
+// extension is not conclusive is the data read and inspected, as it would
+// be for inline values. The format of data on standard input ("@-") cannot
+// be detected without consuming it, so it is reported as an error.
+func DetectFormat(value string, opts ...Option) (Format, error) {
+	o := newOptions(opts...)
+	if format, _ := cutFormatPrefix(value); format != FormatUnknown {
+		return format, nil
+	}
+	switch {
+	case value == "@-":
+		return FormatUnknown, errors.New("the format of standard input cannot be detected without reading it")
+	case strings.HasPrefix(value, "@"):
+		if !o.allowFileAccess {
+			break
+		}
+		if format := formatFromExtension(path.Ext(value), o); format != FormatUnknown {
+			return format, nil
+		}
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		if !o.remoteAccess() {
+			break
+		}
+		if u, err := url.Parse(value); err == nil {
+			if format := formatFromExtension(path.Ext(u.Path), o); format != FormatUnknown {
+				return format, nil
+			}
+		}
+	}
+	format, _, err := readContent(value, o)
+	return format, err
+}
+
+// guessFormat detects the format of the data more aggressively than
+// sniffFormat: if the leading characters are not conclusive, it checks
+// whether the data is valid JSON and then whether it is a YAML mapping or
+// sequence (or scalar, if scalars are allowed); it returns FormatUnknown if
+// none of them fits.
+func guessFormat(content []byte, o *options) Format {
+	if format, err := sniffFormat(content, o); err == nil {
+		return format
+	}
+	if json.Valid(content) {
+		return FormatJSON
+	}
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(content, &document); err == nil && len(document.Content) > 0 {
+		switch document.Content[0].Kind {
+		case yaml.MappingNode, yaml.SequenceNode:
+			return FormatYAML
+		}
+	}
+	return FormatUnknown
+}
+
+// autoDetectFormat tries to decode the data as JSON and then, since YAML is a
+// superset of JSON that would accept most malformed JSON as a string, as
+// YAML; it returns the first format that works.
+func autoDetectFormat(content []byte, o *options) (Format, error) {
+	_, jsonErr := decode(FormatJSON, content, o)
+	if jsonErr == nil {
+		return FormatJSON, nil
+	}
+	_, yamlErr := decode(FormatYAML, content, o)
+	if yamlErr == nil {
+		return FormatYAML, nil
+	}
+	return FormatUnknown, fmt.Errorf("%w: not JSON (%v), nor YAML (%v)", ErrUnrecognisedInline, jsonErr, yamlErr)
+}
+
+// verifyFormat checks that the data starts in a way that is compatible with
+// the given format; formats that can start with almost anything (e.g. CSV)
+// are not checked.
+func verifyFormat(format Format, content []byte, o *options) error {
+	if o.allowComments {
+		content = stripJSONComments(content)
+	}
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return nil
+	}
+	valid := true
+	switch format {
+	case FormatJSON, FormatNDJSON:
+		valid = strings.IndexByte(`{["0123456789tfn`, content[0]) >= 0 ||
+			content[0] == '-' && len(content) > 1 && content[1] >= '0' && content[1] <= '9'
+	case FormatXML:
+		valid = content[0] == '<'
+	case FormatYAML:
+		valid = content[0] != '<'
+	case FormatTOML:
+		valid = content[0] != '{' && content[0] != '<' && !bytes.HasPrefix(content, []byte("---"))
+	}
+	if !valid {
+		start := content
+		if i := bytes.IndexByte(start, '\n'); i >= 0 {
+			start = start[:i]
+		}
+		if len(start) > 20 {
+			start = start[:20]
+		}
+		return fmt.Errorf("%s data cannot start with %q: %w", formatLabel(format), start, ErrFormatMismatch)
+	}
+	return nil
+}
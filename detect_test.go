@@ -0,0 +1,147 @@
+package rawdata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"@./test/struct.json":         FormatJSON,
+		"@./test/nonexisting.yaml":    FormatYAML,
+		"@./test/nonexisting.TOML":    FormatTOML,
+		"@./test/struct.yaml.gz":      FormatYAML,
+		"@./test/array.gz":            FormatJSON,
+		"json:@./test/struct.conf":    FormatJSON,
+		"https://example.com/app.yml": FormatYAML,
+		`{"name": "John"}`:            FormatJSON,
+		"---\nname: John":             FormatYAML,
+		"toml:name = 'John'":          FormatTOML,
+	} {
+		format, err := DetectFormat(input)
+		if err != nil {
+			t.Fatalf("error detecting format of %q: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+	}
+
+	for input, expected := range map[string]error{
+		"@./test/test.txt": ErrUnsupportedFormat,
+		"name: John":       ErrUnrecognisedInline,
+	} {
+		if _, err := DetectFormat(input); !errors.Is(err, expected) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+		}
+	}
+	if _, err := DetectFormat("@-"); err == nil {
+		t.Error("no error detecting format of standard input")
+	}
+	if _, err := DetectFormat("@./test/struct.json", WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("invalid error with file access disabled: %v", err)
+	}
+}
+
+func TestWithSniffUnknownExtensions(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"@./test/Procfile":    FormatYAML,
+		"@./test/.myapp":      FormatJSON,
+		"@./test/struct.conf": FormatJSON,
+		"@./test/test.txt":    FormatYAML,
+	} {
+		if _, err := Unmarshal(input); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("invalid error for %q without sniffing: %v", input, err)
+		}
+		result, format, err := UnmarshalWithFormat(input, WithSniffUnknownExtensions(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q with sniffing: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+		if result == nil {
+			t.Errorf("no result for %q", input)
+		}
+	}
+	if _, err := Unmarshal("@./test/notes.txt", WithSniffUnknownExtensions(true)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("invalid error for unstructured file: %v", err)
+	}
+}
+
+func TestWithAutoDetect(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"name: test\nport: 8080": FormatYAML,
+		"- a\n- b":               FormatYAML,
+		"42":                     FormatJSON,
+		`"hello"`:                FormatJSON,
+		"hello":                  FormatYAML,
+	} {
+		if _, err := Unmarshal(input); !errors.Is(err, ErrUnrecognisedInline) {
+			t.Errorf("invalid error for %q without auto-detection: %v", input, err)
+		}
+		result, format, err := UnmarshalWithFormat(input, WithAutoDetect(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q with auto-detection: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+		if result == nil {
+			t.Errorf("no result for %q", input)
+		}
+	}
+	_, err := Unmarshal("name: [unterminated", WithAutoDetect(true))
+	if !errors.Is(err, ErrUnrecognisedInline) {
+		t.Fatalf("invalid error for undecodable data: %v", err)
+	}
+	if message := err.Error(); !strings.Contains(message, "JSON") || !strings.Contains(message, "YAML") {
+		t.Errorf("error does not report both failures: %v", err)
+	}
+}
+
+func TestWithImplicitYAML(t *testing.T) {
+	result, format, err := UnmarshalWithFormat("name: test\nport: 8080", WithImplicitYAML(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling implicit YAML: %v", err)
+	}
+	if format != FormatYAML {
+		t.Errorf("invalid format: expected yaml, got %v", format)
+	}
+	if object := result.(map[string]interface{}); object["name"] != "test" || object["port"] != 8080 {
+		t.Errorf("invalid result: %v", result)
+	}
+	if _, err := Unmarshal("name: [unterminated", WithImplicitYAML(true)); !errors.As(err, new(*DecodeError)) {
+		t.Errorf("invalid error for malformed implicit YAML: %v", err)
+	}
+	if _, err := Unmarshal("hello", WithImplicitYAML(true)); !errors.Is(err, ErrUnrecognisedInline) {
+		t.Errorf("invalid error for scalar: %v", err)
+	}
+	if _, err := Unmarshal("hello", WithImplicitYAML(true), WithAllowScalars(true)); err != nil {
+		t.Errorf("error unmarshalling allowed scalar: %v", err)
+	}
+	if _, err := Unmarshal("name: test"); !errors.Is(err, ErrUnrecognisedInline) {
+		t.Errorf("invalid error without implicit YAML: %v", err)
+	}
+}
+
+func TestWithVerifyFormat(t *testing.T) {
+	if _, err := Unmarshal("@./test/mislabelled.json", WithVerifyFormat(true)); !errors.Is(err, ErrFormatMismatch) {
+		t.Errorf("invalid error for mislabelled file: %v", err)
+	}
+	if _, err := Unmarshal("yaml:@./test/mislabelled.json", WithVerifyFormat(true)); err != nil {
+		t.Errorf("error unmarshalling mislabelled file with format prefix: %v", err)
+	}
+	for _, input := range []string{
+		"@./test/struct.json",
+		"@./test/struct.yaml",
+		"@./test/struct.toml",
+		"@./test/config.xml",
+		"@./test/commented.jsonc",
+	} {
+		if _, err := Unmarshal(input, WithVerifyFormat(true), WithAllowComments(true), WithAllowTrailingCommas(true)); err != nil {
+			t.Errorf("error unmarshalling %q with verification: %v", input, err)
+		}
+	}
+}
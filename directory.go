@@ -0,0 +1,126 @@
+package rawdata
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isDirectory returns whether the file reference is an existing directory.
+func isDirectory(filename string, o *options) bool {
+	info, err := fs.Stat(o.filesystem(), filename)
+	return err == nil && info.IsDir()
+}
+
+// checkAmbiguous checks that a file reference that names a directory does
+// not also match files in the same parent directory whose names only differ
+// in case from it (e.g. the file "Config" next to the directory "config"):
+// such entries can coexist on case-sensitive filesystems, but users moving
+// between filesystems cannot tell which one they refer to, so the reference
+// is reported as ambiguous, naming all the candidates, rather than resolved
+// to the directory. If the parent directory cannot be listed, there is
+// nothing to compare with and the reference is accepted.
+func checkAmbiguous(dir string, o *options) error {
+	clean := filepath.Clean(dir)
+	parent, base, join := filepath.Dir(clean), filepath.Base(clean), filepath.Join
+	if o.fsys != nil {
+		clean = path.Clean(dir)
+		parent, base, join = path.Dir(clean), path.Base(clean), path.Join
+	}
+	entries, err := fs.ReadDir(o.filesystem(), parent)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), base) {
+			files = append(files, fmt.Sprintf("'%s'", join(parent, entry.Name())))
+		}
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("'%s' %w: it matches directory '%s' and file %s", dir, ErrAmbiguousFile, dir, strings.Join(files, ", "))
+	}
+	return nil
+}
+
+// loadDirectory reads all the files in the given directory (but not in its
+// subdirectories), in sorted filename order, and deep-merges their contents
+// (see Merge) into a single document, so that the files coming later (e.g.
+// "20-local.yaml") override the ones coming earlier (e.g. "10-base.json"):
+// nested objects are merged key by key, whereas scalars and arrays are
+// replaced wholesale. The files can be in any format, each detected from its
+// extension, unless forced by a prefix; files in unsupported formats are
+// skipped, unless configured otherwise (see WithSkipUnsupportedFiles), and so
+// are empty files. The result is encoded as JSON, or in the forced format.
+func loadDirectory(dir string, forced Format, o *options) (Format, []byte, error) {
+	if err := checkAllowedRoot(dir, o); err != nil {
+		return FormatUnknown, nil, err
+	}
+	if err := checkAmbiguous(dir, o); err != nil {
+		return FormatUnknown, nil, err
+	}
+	entries, err := fs.ReadDir(o.filesystem(), dir)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error reading directory '%s': %w", dir, err)
+	}
+	// merging needs plain maps, and numbers must survive the round trip
+	generic := *o
+	generic.orderedMaps = false
+	generic.numberMode = NumberModeDefault
+	generic.useNumber = true
+	var merged interface{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filename := path.Join(dir, entry.Name())
+		if o.fsys == nil {
+			filename = filepath.Join(dir, entry.Name())
+		}
+		format, content, err := loadFile(filename, forced, o)
+		if errors.Is(err, ErrUnsupportedFormat) && o.skipUnsupportedFiles {
+			continue
+		} else if err != nil {
+			return FormatUnknown, nil, err
+		}
+		if isEmpty(content) {
+			continue
+		}
+		if forced != FormatUnknown {
+			format = forced
+		} else if format == FormatUnknown {
+			// a compressed file with no conclusive inner extension
+			if format, err = sniffFormat(content, o); err != nil {
+				if o.skipUnsupportedFiles {
+					continue
+				}
+				return FormatUnknown, nil, fmt.Errorf("error detecting format of file '%s': %w", filename, err)
+			}
+		}
+		value, err := decode(format, content, &generic)
+		if err != nil {
+			return FormatUnknown, nil, fmt.Errorf("error decoding '%s': %w", filename, err)
+		}
+		if merged == nil {
+			merged = value
+		} else if merged, err = Merge(merged, value); err != nil {
+			return FormatUnknown, nil, fmt.Errorf("error merging '%s': %w", filename, err)
+		}
+	}
+	if merged == nil {
+		// no data at all, which may be allowed (see WithAllowEmpty)
+		return forced, nil, nil
+	}
+	encoding := forced
+	if encoding == FormatUnknown {
+		encoding = FormatJSON
+	}
+	content, err := Marshal(merged, encoding)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error merging directory '%s': %w", dir, err)
+	}
+	return encoding, content, nil
+}
@@ -0,0 +1,120 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnmarshalMergeDirectory(t *testing.T) {
+	expected := map[string]interface{}{
+		"server":   map[string]interface{}{"host": "localhost", "port": float64(9090), "tls": true},
+		"features": []interface{}{"c"},
+		"debug":    false,
+	}
+	for _, input := range []string{"@./test/conf.d/", "@./test/conf.d"} {
+		result, err := Unmarshal(input, WithMergeDirectory(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling directory %q: %v", input, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for %q: expected %v, got %v", input, expected, result)
+		}
+	}
+	type config struct {
+		Server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+			TLS  bool   `json:"tls"`
+		} `json:"server"`
+		Features []string `json:"features"`
+	}
+	c := config{}
+	if err := UnmarshalInto("@./test/conf.d/", &c, WithMergeDirectory(true)); err != nil {
+		t.Fatalf("error unmarshalling directory into struct: %v", err)
+	}
+	if c.Server.Host != "localhost" || c.Server.Port != 9090 || !c.Server.TLS || !reflect.DeepEqual(c.Features, []string{"c"}) {
+		t.Errorf("invalid struct: %+v", c)
+	}
+	if _, err := Unmarshal("@./test/conf.d/"); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("expected ErrIsDirectory without merging, got %v", err)
+	}
+	if _, err := Unmarshal("@./test/conf.d/", WithMergeDirectory(true), WithSkipUnsupportedFiles(false)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat for README.md, got %v", err)
+	}
+}
+
+func TestUnmarshalMergeDirectoryFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/a.yaml": {Data: []byte("list: [1]\nname: a\n")},
+		"conf.d/b.json": {Data: []byte(`{"name": "b", "big": 12345678901234567890}`)},
+		"empty/.keep":   {Data: []byte{}},
+		"mixed/a.yaml":  {Data: []byte("name: a\n")},
+		"mixed/b.json":  {Data: []byte(`[1, 2]`)},
+	}
+	result, err := Unmarshal("@conf.d", WithFS(fsys), WithMergeDirectory(true), WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling directory: %v", err)
+	}
+	if name, _ := GetPath(result, "name"); name != "b" {
+		t.Errorf("invalid name: %v", name)
+	}
+	if big, _ := GetPath(result, "big"); big != json.Number("12345678901234567890") {
+		t.Errorf("invalid number: %v", big)
+	}
+	if _, err := Unmarshal("@empty", WithFS(fsys), WithMergeDirectory(true)); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("expected ErrEmptyContent for directory without data, got %v", err)
+	}
+	if result, err := Unmarshal("@empty", WithFS(fsys), WithMergeDirectory(true), WithAllowEmpty(true)); err != nil || result != nil {
+		t.Errorf("invalid result for empty directory: %v (error: %v)", result, err)
+	}
+	if _, err := Unmarshal("@mixed", WithFS(fsys), WithMergeDirectory(true)); err == nil {
+		t.Error("expected error merging an object and an array")
+	}
+}
+
+func TestAmbiguousDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "config"), 0o755); err != nil {
+		t.Fatalf("error creating directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "app.json"), []byte(`{"a": 1}`), 0o644); err != nil {
+		t.Fatalf("error creating file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Config"), []byte(`{"b": 2}`), 0o644); err != nil {
+		t.Fatalf("error creating file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CONFIG")); err == nil {
+		t.Skip("case-insensitive filesystem")
+	}
+	// an exact file match is always preferred
+	result, err := Unmarshal("json:@" + filepath.Join(dir, "Config"))
+	if err != nil || !reflect.DeepEqual(result, map[string]interface{}{"b": float64(2)}) {
+		t.Errorf("invalid exact match: %v, %v", result, err)
+	}
+	for _, input := range []string{"json:@" + filepath.Join(dir, "config"), "json:@" + filepath.Join(dir, "config") + "/"} {
+		for _, opts := range [][]Option{nil, {WithMergeDirectory(true)}} {
+			_, err := Unmarshal(input, opts...)
+			if !errors.Is(err, ErrAmbiguousFile) || !strings.Contains(err.Error(), "'"+filepath.Join(dir, "Config")+"'") {
+				t.Errorf("invalid error for %q: %v", input, err)
+			}
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"etc/conf/app.json": {Data: []byte(`{"a": 1}`)},
+		"etc/CONF":          {Data: []byte(`{"b": 2}`)},
+		"etc/other/a.json":  {Data: []byte(`{"c": 3}`)},
+	}
+	if _, err := Unmarshal("@etc/conf", WithFS(fsys), WithMergeDirectory(true)); !errors.Is(err, ErrAmbiguousFile) || !strings.Contains(err.Error(), "'etc/CONF'") {
+		t.Errorf("invalid error for directory in filesystem: %v", err)
+	}
+	if _, err := Unmarshal("@etc/other", WithFS(fsys)); !errors.Is(err, ErrIsDirectory) {
+		t.Errorf("invalid error for unambiguous directory: %v", err)
+	}
+}
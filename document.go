@@ -0,0 +1,144 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a YAML document that keeps the comments in the original data;
+// it is returned by Unmarshal in place of the decoded value when the
+// WithPreserveComments option is set and the data is in YAML format, so that
+// it can be modified (see Set) and written back with Marshal, comments
+// included.
+type Document struct {
+	node yaml.Node
+	o    options
+}
+
+// newDocument parses the YAML data into a Document.
+func newDocument(content []byte, o *options) (*Document, error) {
+	d := &Document{o: *o}
+	if err := yaml.Unmarshal(content, &d.node); err != nil {
+		return nil, newDecodeError(FormatYAML, content, err)
+	}
+	if d.node.Kind == 0 {
+		// empty document (e.g. only comments)
+		d.node.Kind = yaml.DocumentNode
+	}
+	return d, nil
+}
+
+// Node returns the node tree of the document, which can be inspected and
+// modified directly; the root of the document is its first child, if any.
+func (d *Document) Node() *yaml.Node {
+	return &d.node
+}
+
+// Value returns the value represented by the document, decoded as it would
+// be by Unmarshal without the WithPreserveComments option.
+func (d *Document) Value() (interface{}, error) {
+	return decodeYAMLDocument(&d.node, &d.o)
+}
+
+// Set replaces the value at the given path in the document (see GetPath for
+// the syntax of paths, whose separator can be set via WithSeparator), keeping
+// the comments attached to the old value; missing keys are added to their
+// objects, creating intermediate objects as needed, whereas array indexes
+// must already exist.
+func (d *Document) Set(path string, value interface{}) error {
+	replacement := &yaml.Node{}
+	if err := replacement.Encode(value); err != nil {
+		return fmt.Errorf("error encoding value for '%s': %w", path, err)
+	}
+	if len(d.node.Content) == 0 {
+		d.node.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	} else if root := d.node.Content[0]; root.Kind == yaml.ScalarNode && root.ShortTag() == "!!null" {
+		// an explicitly empty document
+		d.node.Content[0] = withComments(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, root)
+	}
+	segments := strings.Split(path, d.o.separator)
+	parent := d.node.Content[0]
+	for i, segment := range segments {
+		if parent.Kind == yaml.AliasNode {
+			return fmt.Errorf("cannot set '%s': '%s' is an alias", path, strings.Join(segments[:i], d.o.separator))
+		}
+		last := i == len(segments)-1
+		switch parent.Kind {
+		case yaml.MappingNode:
+			j := mappingIndex(parent, segment)
+			if j < 0 {
+				key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}
+				child := replacement
+				if !last {
+					child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				}
+				parent.Content = append(parent.Content, key, child)
+				parent = child
+				continue
+			}
+			if last {
+				parent.Content[j+1] = withComments(replacement, parent.Content[j+1])
+			} else {
+				parent = parent.Content[j+1]
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(parent.Content) {
+				return fmt.Errorf("cannot set '%s': invalid index '%s' in array of %d elements", path, segment, len(parent.Content))
+			}
+			if last {
+				parent.Content[index] = withComments(replacement, parent.Content[index])
+			} else {
+				parent = parent.Content[index]
+			}
+		default:
+			return fmt.Errorf("cannot set '%s': '%s' is a scalar", path, strings.Join(segments[:i], d.o.separator))
+		}
+	}
+	return nil
+}
+
+// MarshalYAML returns the node tree of the document, so that it can be
+// embedded into other values being marshalled into YAML; the comments before
+// and after the document itself are lost, unless the document is marshalled
+// on its own.
+func (d *Document) MarshalYAML() (interface{}, error) {
+	if len(d.node.Content) == 0 {
+		return nil, nil
+	}
+	return d.node.Content[0], nil
+}
+
+// MarshalJSON returns the JSON representation of the value of the document;
+// comments are lost, since JSON does not support them.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	value, err := d.Value()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// mappingIndex returns the index of the node holding the given key in the
+// contents of a mapping node, or -1 if the key is not there.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// withComments copies the comments of the old node onto the new one, and
+// returns the new node.
+func withComments(node *yaml.Node, old *yaml.Node) *yaml.Node {
+	node.HeadComment = old.HeadComment
+	node.LineComment = old.LineComment
+	node.FootComment = old.FootComment
+	return node
+}
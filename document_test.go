@@ -0,0 +1,96 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalPreserveComments(t *testing.T) {
+	result, err := Unmarshal("@./test/commented.yaml", WithPreserveComments(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	document, ok := result.(*Document)
+	if !ok {
+		t.Fatalf("invalid result type: %T", result)
+	}
+	if err := document.Set("server.port", 80); err != nil {
+		t.Fatalf("error setting port: %v", err)
+	}
+	if err := document.Set("server.tags.1", "internal"); err != nil {
+		t.Fatalf("error setting tag: %v", err)
+	}
+	if err := document.Set("server.tls.enabled", true); err != nil {
+		t.Fatalf("error adding new key: %v", err)
+	}
+	data, err := Marshal(document, FormatYAML)
+	if err != nil {
+		t.Fatalf("error marshalling: %v", err)
+	}
+	for _, expected := range []string{
+		"# Service configuration, edit with care.",
+		"# the address to listen on",
+		"port: 80 # change to 80 in production",
+		"- web # public",
+		"- internal",
+		"enabled: true",
+		"# end of configuration",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, data)
+		}
+	}
+	value, err := document.Value()
+	if err != nil {
+		t.Fatalf("error getting value: %v", err)
+	}
+	if port, _ := GetPath(value, "server.port"); port != 80 {
+		t.Errorf("invalid port in value: %v", port)
+	}
+	data, err = Marshal(document, FormatJSON)
+	if err != nil {
+		t.Fatalf("error marshalling into JSON: %v", err)
+	}
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if tags, _ := GetPath(decoded, "server.tags"); !reflect.DeepEqual(tags, []interface{}{"web", "internal"}) {
+		t.Errorf("invalid tags in JSON output: %v", tags)
+	}
+	for _, path := range []string{"server.tags.2", "server.host.name", "server.tags.x"} {
+		if err := document.Set(path, 1); err == nil {
+			t.Errorf("expected error setting %q", path)
+		}
+	}
+}
+
+func TestUnmarshalPreserveCommentsOtherFormats(t *testing.T) {
+	result, err := Unmarshal(`{"a": 1}`, WithPreserveComments(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if _, ok := result.(map[string]interface{}); !ok {
+		t.Errorf("invalid result type for JSON: %T", result)
+	}
+	result, err = Unmarshal("yaml:", WithPreserveComments(true), WithAllowEmpty(true))
+	if err != nil || result != nil {
+		t.Errorf("invalid result for empty YAML: %v (error: %v)", result, err)
+	}
+	result, err = Unmarshal("---\n# nothing here\n", WithPreserveComments(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling comments only: %v", err)
+	}
+	document, ok := result.(*Document)
+	if !ok {
+		t.Fatalf("invalid result type for comments only: %T", result)
+	}
+	if err := document.Set("a/b", "c"); err != nil {
+		t.Fatalf("error setting value in empty document: %v", err)
+	}
+	if value, _ := document.Value(); !reflect.DeepEqual(value, map[string]interface{}{"a/b": "c"}) {
+		t.Errorf("invalid value: %v", value)
+	}
+}
@@ -0,0 +1,146 @@
+package rawdata
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+var (
+	// utf8BOM is the UTF-8 encoding of the byte order mark (U+FEFF), which
+	// some editors write at the beginning of UTF-8 files.
+	utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+	// utf16LEBOM is the UTF-16 little endian encoding of the byte order mark.
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	// utf16BEBOM is the UTF-16 big endian encoding of the byte order mark.
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// unsupportedBOMs are the byte order marks of the encodings that cannot be
+// transcoded; UTF-32 little endian comes first, since it starts with the
+// UTF-16 little endian byte order mark.
+var unsupportedBOMs = []struct {
+	encoding string
+	bom      []byte
+}{
+	{"UTF-32LE", []byte{0xFF, 0xFE, 0x00, 0x00}},
+	{"UTF-32BE", []byte{0x00, 0x00, 0xFE, 0xFF}},
+	{"UTF-7", []byte{0x2B, 0x2F, 0x76}},
+	{"UTF-EBCDIC", []byte{0xDD, 0x73, 0x66, 0x73}},
+	{"GB-18030", []byte{0x84, 0x31, 0x95, 0x33}},
+}
+
+// EncodingError is returned when the data is not in a supported text
+// encoding, e.g. because it was saved as UTF-32 or Latin-1; it matches
+// ErrEncoding with errors.Is.
+type EncodingError struct {
+	// Encoding is the name of the detected encoding, or a description of
+	// the problem if the encoding could not be told exactly.
+	Encoding string
+	// Offset is the position of the first offending byte in the data.
+	Offset int
+	// Bytes are the first few offending bytes.
+	Bytes []byte
+}
+
+// Error implements the error interface.
+func (e *EncodingError) Error() string {
+	return fmt.Sprintf("%v: %s (bytes % x at offset %d)", ErrEncoding, e.Encoding, e.Bytes, e.Offset)
+}
+
+// Is returns whether the target is ErrEncoding.
+func (e *EncodingError) Is(target error) bool {
+	return target == ErrEncoding
+}
+
+// newEncodingError returns an EncodingError for the content, with up to 8
+// bytes starting at the given offset.
+func newEncodingError(encoding string, content []byte, offset int) *EncodingError {
+	end := offset + 8
+	if end > len(content) {
+		end = len(content)
+	}
+	return &EncodingError{Encoding: encoding, Offset: offset, Bytes: append([]byte(nil), content[offset:end]...)}
+}
+
+// decodeText normalises the content to UTF-8 without a byte order mark:
+// UTF-16 content (either little or big endian) is recognised by its byte
+// order mark and transcoded, whereas content without a byte order mark is
+// assumed to be UTF-8 already. The byte order marks of other encodings
+// (e.g. UTF-32) result in an EncodingError.
+func decodeText(content []byte) ([]byte, error) {
+	for _, unsupported := range unsupportedBOMs {
+		if bytes.HasPrefix(content, unsupported.bom) {
+			return nil, newEncodingError(unsupported.encoding, content, 0)
+		}
+	}
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return content[len(utf8BOM):], nil
+	case bytes.HasPrefix(content, utf16LEBOM), bytes.HasPrefix(content, utf16BEBOM):
+		decoded, err := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder().Bytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding UTF-16 data: %w", err)
+		}
+		return decoded, nil
+	default:
+		return content, nil
+	}
+}
+
+// decodeTextReader is the streaming counterpart of decodeText: it returns a
+// reader of the UTF-8 data without a byte order mark.
+func decodeTextReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	// errors (e.g. a short read) are reported by the first actual read
+	start, _ := buffered.Peek(4)
+	for _, unsupported := range unsupportedBOMs {
+		if bytes.HasPrefix(start, unsupported.bom) {
+			return nil, newEncodingError(unsupported.encoding, start, 0)
+		}
+	}
+	switch {
+	case bytes.HasPrefix(start, utf8BOM):
+		buffered.Discard(len(utf8BOM))
+		return buffered, nil
+	case bytes.HasPrefix(start, utf16LEBOM), bytes.HasPrefix(start, utf16BEBOM):
+		return transform.NewReader(buffered, unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()), nil
+	default:
+		return buffered, nil
+	}
+}
+
+// checkEncoding returns an EncodingError if the content of a built-in format,
+// all of which are text, is not valid UTF-8 (e.g. because it is Latin-1) or
+// contains NUL bytes (e.g. because it is UTF-16 without a byte order mark);
+// registered formats may be binary, so they are not checked.
+func checkEncoding(format Format, content []byte) error {
+	if !isBuiltinFormat(format) {
+		return nil
+	}
+	if offset := bytes.IndexByte(content, 0); offset >= 0 {
+		encoding := "UTF-16 or UTF-32 without byte order mark"
+		if offset > 0 && len(content) > 1 && content[1] == 0 {
+			encoding = "UTF-16LE or UTF-32LE without byte order mark"
+		} else if offset == 0 && len(content) > 1 && content[1] != 0 {
+			encoding = "UTF-16BE without byte order mark"
+		}
+		return newEncodingError(encoding, content, offset)
+	}
+	if utf8.Valid(content) {
+		return nil
+	}
+	for offset := 0; offset < len(content); {
+		r, size := utf8.DecodeRune(content[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			return newEncodingError("invalid UTF-8, possibly Latin-1 or Windows-1252", content, offset)
+		}
+		offset += size
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package rawdata
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalWithUTF8BOM(t *testing.T) {
+	for _, input := range []string{"@./test/bom.json", "\xef\xbb\xbf" + `{"name": "John", "surname": "Doe", "age": 23}`} {
+		result := &s{}
+		if err := UnmarshalInto(input, result); err != nil {
+			t.Fatalf("error unmarshalling BOM-prefixed input: %v", err)
+		}
+		if result.Name != "John" || result.Age != 23 {
+			t.Fatalf("invalid value unmarshalled from BOM-prefixed input: %+v", result)
+		}
+		if _, err := Unmarshal(input); err != nil {
+			t.Fatalf("error unmarshalling BOM-prefixed input: %v", err)
+		}
+	}
+	if _, err := UnmarshalReader(strings.NewReader("\xef\xbb\xbf---\nname: John\n"), FormatUnknown); err != nil {
+		t.Fatalf("error unmarshalling BOM-prefixed reader: %v", err)
+	}
+	if _, err := Unmarshal("@./test/[bs]*.json"); err != nil {
+		t.Fatalf("error unmarshalling glob including BOM-prefixed file: %v", err)
+	}
+}
+
+func TestUnmarshalUTF16(t *testing.T) {
+	for _, input := range []string{"@./test/utf16le.yaml", "@./test/utf16be.json"} {
+		result := &s{}
+		if err := UnmarshalInto(input, result); err != nil {
+			t.Fatalf("error unmarshalling UTF-16 file %q: %v", input, err)
+		}
+		if result.Name != "John" || result.Surname != "Doe" || result.Age != 23 {
+			t.Fatalf("invalid value unmarshalled from UTF-16 file %q: %+v", input, result)
+		}
+	}
+	format, err := DetectFormat("@./test/utf16be.json")
+	if err != nil || format != FormatJSON {
+		t.Fatalf("invalid format detected for UTF-16 file: %v (error: %v)", format, err)
+	}
+}
+
+func TestUnmarshalUnsupportedEncoding(t *testing.T) {
+	testCases := []struct {
+		input    string
+		encoding string
+		offset   int
+	}{
+		{input: "@./test/utf32le.json", encoding: "UTF-32LE", offset: 0},
+		{input: "@./test/latin1.csv", encoding: "Latin-1", offset: 13},
+		{input: "@./test/utf16le-nobom.yaml", encoding: "UTF-16LE", offset: 1},
+		{input: "json:{\"name\": \"caf\xe9\"}", encoding: "Latin-1", offset: 13},
+	}
+	for _, test := range testCases {
+		_, err := Unmarshal(test.input)
+		if !errors.Is(err, ErrEncoding) {
+			t.Fatalf("expected encoding error for %q, got %v", test.input, err)
+		}
+		var encodingError *EncodingError
+		if !errors.As(err, &encodingError) {
+			t.Fatalf("expected EncodingError for %q, got %T", test.input, err)
+		}
+		if !strings.Contains(encodingError.Encoding, test.encoding) || encodingError.Offset != test.offset || len(encodingError.Bytes) == 0 {
+			t.Errorf("invalid error for %q: %+v", test.input, encodingError)
+		}
+	}
+	if _, err := UnmarshalReader(bytes.NewReader([]byte("---\nname: caf\xe9\n")), FormatUnknown); !errors.Is(err, ErrEncoding) {
+		t.Errorf("expected encoding error from reader, got %v", err)
+	}
+}
@@ -0,0 +1,445 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrFileNotFound is returned when a value refers to a file that does
+	// not exist.
+	ErrFileNotFound = errors.New("file not found")
+	// ErrIsDirectory is returned when a value refers to a directory instead
+	// of a file.
+	ErrIsDirectory = errors.New("is a directory, not a file")
+	// ErrUnsupportedFormat is returned when the format of the data (e.g. as
+	// inferred from the file extension) is not supported.
+	ErrUnsupportedFormat = errors.New("unsupported data format")
+	// ErrUnrecognisedInline is returned when the format of inline data
+	// cannot be detected.
+	ErrUnrecognisedInline = errors.New("unrecognisable input format in inline data")
+	// ErrPathEscape is returned when a value refers to a file outside of
+	// the allowed root directory.
+	ErrPathEscape = errors.New("path escapes the allowed root directory")
+	// ErrFileTooLarge is returned when the data read from a file, from
+	// standard input or from a remote URL exceeds the maximum size.
+	ErrFileTooLarge = errors.New("data too large")
+	// ErrFileAccessDisabled is returned when a value refers to a file but
+	// file access has been disabled.
+	ErrFileAccessDisabled = errors.New("file access is disabled")
+	// ErrRemoteAccessDisabled is returned when a value refers to a remote URL
+	// or to a URI with a registered scheme but remote access has been
+	// disabled.
+	ErrRemoteAccessDisabled = errors.New("remote access is disabled")
+	// ErrEmptyContent is returned when the data is empty or only contains
+	// whitespace, unless empty content is allowed (see WithAllowEmpty).
+	ErrEmptyContent = errors.New("empty content")
+	// ErrSymlink is returned when a value refers to a symbolic link but
+	// following symbolic links has been disabled.
+	ErrSymlink = errors.New("is a symbolic link")
+	// ErrNotArray is returned when the data must be an array (e.g. to be
+	// streamed by UnmarshalStream) but it is not.
+	ErrNotArray = errors.New("data is not an array")
+	// ErrMaxDepthExceeded is returned when the data is nested more deeply
+	// than allowed (see WithMaxDepth).
+	ErrMaxDepthExceeded = errors.New("maximum nesting depth exceeded")
+	// ErrAliasBudgetExceeded is returned when the aliases in YAML data would
+	// expand to too many nodes (see WithMaxAliasExpansion).
+	ErrAliasBudgetExceeded = errors.New("alias expansion budget exceeded")
+	// ErrInvalidDataURI is returned when a value starting with "data:" is not
+	// a well-formed data URI.
+	ErrInvalidDataURI = errors.New("invalid data URI")
+	// ErrFormatMismatch is returned when the data does not look like it is
+	// in the format detected from the file extension (see WithVerifyFormat).
+	ErrFormatMismatch = errors.New("data does not match the expected format")
+	// ErrEncoding is returned when the data is not in a supported text
+	// encoding (UTF-8, or UTF-16 with a byte order mark); the actual error is
+	// an EncodingError, which names the detected encoding.
+	ErrEncoding = errors.New("unsupported text encoding")
+	// ErrInlineNotAllowed is returned when a value does not refer to a file
+	// but files are required (see WithRequireFile).
+	ErrInlineNotAllowed = errors.New("data must be read from a file")
+	// ErrAmbiguousFile is returned when a file reference names a directory
+	// but also matches a file whose name only differs in case from it.
+	ErrAmbiguousFile = errors.New("is an ambiguous file reference")
+)
+
+// errTrailingData is the cause of the DecodeError returned when there is
+// more data after the top-level JSON value.
+var errTrailingData = errors.New("invalid data after top-level value")
+
+// DecodeError is returned when the data cannot be decoded in its format,
+// e.g. because of a syntax error or a type mismatch; it records the position
+// of the problem, when known, and wraps the error returned by the underlying
+// library, so that the latter can still be inspected with errors.As. For
+// type mismatches it also records the field and the types involved (see
+// Field, Expected and Got), named the same way whatever the format, so that
+// they can all be handled alike: this covers JSON, YAML and TOML, and the
+// formats decoded into targets via their JSON representation (CSV,
+// properties and NDJSON).
+type DecodeError struct {
+	// DataFormat is the format of the data.
+	DataFormat Format
+	// Line is the line (starting at 1) where the problem is, or 0 if it is
+	// not known.
+	Line int
+	// Column is the column (starting at 1, in bytes) where the problem is,
+	// or 0 if it is not known.
+	Column int
+	// Err is the underlying error.
+	Err error
+	// field is the path of the field whose value has the wrong type.
+	field string
+	// expected is the type the value should have had.
+	expected string
+	// got is the type of the value in the data.
+	got string
+}
+
+// Error implements the error interface.
+func (e *DecodeError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("error unmarshalling from %s at line %d, column %d: %v", formatLabel(e.DataFormat), e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("error unmarshalling from %s at line %d: %v", formatLabel(e.DataFormat), e.Line, e.Err)
+	default:
+		return fmt.Sprintf("error unmarshalling from %s: %v", formatLabel(e.DataFormat), e.Err)
+	}
+}
+
+// Format returns the format of the data.
+func (e *DecodeError) Format() Format {
+	return e.DataFormat
+}
+
+// Field returns the path of the field whose value has the wrong type, with
+// the keys and array indexes separated by dots (e.g. "servers.0.port"), or
+// an empty string if the error is not a type mismatch, the field is not
+// known or the mismatch is at the top level. TOML only reports the keys,
+// with no array indexes.
+func (e *DecodeError) Field() string {
+	return e.field
+}
+
+// Expected returns the type the value should have had: "string", "integer",
+// "float", "bool", "timestamp", "array" or "object" (for maps and structs),
+// or the Go type (e.g. "chan int") if it is none of them; it returns an empty
+// string if the error is not a type mismatch.
+func (e *DecodeError) Expected() string {
+	return e.expected
+}
+
+// Got returns the type of the value in the data: "string", "integer",
+// "float", "number" (if the data does not tell integers from floats),
+// "bool", "null", "timestamp", "binary", "array" or "object"; it returns an
+// empty string if the error is not a type mismatch.
+func (e *DecodeError) Got() string {
+	return e.got
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// yamlLine matches the line number in the messages of YAML errors.
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// newDecodeError returns a DecodeError for the given error, extracting the
+// position of the problem from it, if possible; content is the data that was
+// being decoded, and is used to convert byte offsets into lines and columns.
+func newDecodeError(format Format, content []byte, err error) *DecodeError {
+	e := &DecodeError{DataFormat: format, Err: err}
+	var (
+		jsonSyntax *json.SyntaxError
+		jsonType   *json.UnmarshalTypeError
+		yamlType   *yaml.TypeError
+		tomlParse  toml.ParseError
+		csvParse   *csv.ParseError
+		xmlSyntax  *xml.SyntaxError
+	)
+	switch {
+	case errors.As(err, &jsonSyntax):
+		e.Line, e.Column = position(content, jsonSyntax.Offset)
+	case errors.As(err, &jsonType):
+		e.Line, e.Column = position(content, jsonType.Offset)
+		e.field, e.got = jsonType.Field, jsonValueType(jsonType.Value)
+		if jsonType.Value == "number" && jsonType.Offset > 0 && jsonType.Offset <= int64(len(content)) {
+			// the literal is not reported, but it ends at the offset
+			before := content[:jsonType.Offset]
+			start := bytes.LastIndexFunc(before, func(r rune) bool { return !strings.ContainsRune("+-.0123456789eE", r) })
+			e.got = jsonValueType("number " + string(before[start+1:]))
+		}
+		if jsonType.Type != nil {
+			e.expected = typeName(jsonType.Type)
+		}
+	case errors.As(err, &yamlType) && len(yamlType.Errors) > 0:
+		// only the first mismatch is reported
+		if match := yamlMismatch.FindStringSubmatch(yamlType.Errors[0]); match != nil {
+			e.Line, _ = strconv.Atoi(match[1])
+			e.got = yamlTypes[match[2]]
+			e.expected = goTypeName(match[4])
+			e.field = yamlField(content, e.Line, match[3])
+		} else if match := yamlLine.FindStringSubmatch(yamlType.Errors[0]); match != nil {
+			e.Line, _ = strconv.Atoi(match[1])
+		}
+	case errors.As(err, &tomlParse):
+		e.Line, e.Column = tomlParse.Position.Line, tomlParse.Position.Col
+	case errors.As(err, &csvParse):
+		e.Line, e.Column = csvParse.Line, csvParse.Column
+	case errors.As(err, &xmlSyntax):
+		e.Line = xmlSyntax.Line
+	case format == FormatYAML:
+		// the YAML library only reports lines, in the error messages
+		if match := yamlLine.FindStringSubmatch(err.Error()); match != nil {
+			e.Line, _ = strconv.Atoi(match[1])
+		}
+	case format == FormatTOML:
+		// type mismatches are only reported in the error messages
+		if match := tomlMismatch.FindStringSubmatch(err.Error()); match != nil {
+			e.Line, _ = strconv.Atoi(match[1])
+			e.field, e.got, e.expected = match[2], goTypeName(match[3]), tomlTypes[match[4]]
+			if e.expected == "" {
+				e.expected = match[4]
+			}
+		}
+	}
+	return e
+}
+
+var (
+	// yamlMismatch matches the line, the tag, the value (only quoted for
+	// scalars) and the destination type in the messages of YAML type errors.
+	yamlMismatch = regexp.MustCompile("^line (\\d+): cannot unmarshal !!(\\w+)(?: `(.*)`)? into (.+)$")
+	// tomlMismatch matches the line, the key and the types in the messages
+	// of TOML type errors.
+	tomlMismatch = regexp.MustCompile(`line (\d+) \(last key "(.*)"\): incompatible types: TOML value has type (.+); destination has type (\w+)`)
+	// yamlTypes maps the YAML tags to the names of the types used in
+	// DecodeErrors.
+	yamlTypes = map[string]string{
+		"str":       "string",
+		"int":       "integer",
+		"float":     "float",
+		"bool":      "bool",
+		"null":      "null",
+		"seq":       "array",
+		"map":       "object",
+		"timestamp": "timestamp",
+		"binary":    "binary",
+	}
+	// tomlTypes maps the kinds of destinations named in TOML type errors to
+	// the names of the types used in DecodeErrors.
+	tomlTypes = map[string]string{
+		"string":  "string",
+		"integer": "integer",
+		"float":   "float",
+		"boolean": "bool",
+		"slice":   "array",
+		"array":   "array",
+		"map":     "object",
+		"struct":  "object",
+	}
+)
+
+// typeName returns the name of the given Go type as used in DecodeErrors.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "timestamp"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return t.String()
+	}
+}
+
+// goTypeName returns the name used in DecodeErrors of the Go type with the
+// given name, as reported in error messages; types that cannot be told from
+// their names alone (e.g. "config.Server") are left as they are, unless the
+// type of the target is known (see withTargetType).
+func goTypeName(name string) string {
+	name = strings.TrimLeft(name, "*")
+	switch {
+	case name == "string":
+		return "string"
+	case name == "bool":
+		return "bool"
+	case name == "float32" || name == "float64":
+		return "float"
+	case strings.HasPrefix(name, "int") || strings.HasPrefix(name, "uint"):
+		return "integer"
+	case name == "time.Time" || strings.HasPrefix(name, "toml.Local"):
+		return "timestamp"
+	case strings.HasPrefix(name, "["):
+		return "array"
+	case strings.HasPrefix(name, "map["):
+		return "object"
+	default:
+		return name
+	}
+}
+
+// jsonValueType returns the name used in DecodeErrors of the type of the
+// value described in a JSON type error, e.g. "number 1.5".
+func jsonValueType(value string) string {
+	kind, literal, _ := strings.Cut(value, " ")
+	if kind != "number" || literal == "" {
+		return kind
+	}
+	if strings.ContainsAny(literal, ".eE") {
+		return "float"
+	}
+	return "integer"
+}
+
+// withTargetType sets the expected type of a type mismatch reported by a
+// library that only names it (YAML) or its kind (TOML) to that of the field
+// in the target, found by following the path of the field with the given
+// struct tag; it returns the error.
+func withTargetType(e *DecodeError, target interface{}, tag string) *DecodeError {
+	if e.expected == "" || e.field == "" {
+		return e
+	}
+	fields := unknownKeyWalker{tag: tag}
+	t := reflect.TypeOf(target)
+	for _, key := range strings.Split(e.field, ".") {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			// the key is an index, or else the path skips it (as in TOML)
+			t = t.Elem()
+			if _, err := strconv.Atoi(key); err == nil {
+				continue
+			}
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+		}
+		switch {
+		case t == nil:
+			return e
+		case t.Kind() == reflect.Struct:
+			t, _ = fields.lookup(fields.fields(t), key)
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+		default:
+			return e
+		}
+	}
+	if t != nil {
+		e.expected = typeName(t)
+	}
+	return e
+}
+
+// yamlField returns the path of the innermost node at the given line of the
+// YAML data that matches the value quoted in a type error: a scalar starting
+// with it (long values are truncated) or, if there is no value, a mapping or
+// a sequence; it returns an empty string if there is none.
+func yamlField(content []byte, line int, value string) string {
+	document := yaml.Node{}
+	if yaml.Unmarshal(content, &document) != nil {
+		return ""
+	}
+	value = strings.TrimSuffix(value, "...")
+	var find func(node *yaml.Node, path string) (string, bool)
+	find = func(node *yaml.Node, path string) (string, bool) {
+		// nested nodes may start on the same line, so children come first
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				if field, ok := find(child, path); ok {
+					return field, true
+				}
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if field, ok := find(node.Content[i+1], joinPath(path, node.Content[i].Value, ".")); ok {
+					return field, true
+				}
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				if field, ok := find(child, joinPath(path, strconv.Itoa(i), ".")); ok {
+					return field, true
+				}
+			}
+		}
+		if node.Kind != yaml.DocumentNode && node.Line == line {
+			if node.Kind == yaml.ScalarNode && value != "" && strings.HasPrefix(node.Value, value) ||
+				node.Kind != yaml.ScalarNode && value == "" {
+				return path, true
+			}
+		}
+		return "", false
+	}
+	field, _ := find(&document, "")
+	return field
+}
+
+// position converts a byte offset in the content into a line and a column,
+// both starting at 1; the offset is that of the byte after the problem, as
+// reported by the JSON library.
+func position(content []byte, offset int64) (int, int) {
+	if offset <= 0 || content == nil {
+		return 0, 0
+	}
+	if offset > int64(len(content)) {
+		offset = int64(len(content))
+	}
+	before := content[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
+
+// formatLabel returns the name of the format as used in error messages.
+func formatLabel(format Format) string {
+	switch format {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	case FormatTOML:
+		return "TOML"
+	case FormatCSV:
+		return "CSV"
+	case FormatXML:
+		return "XML"
+	case FormatNDJSON:
+		return "NDJSON"
+	default:
+		return format.String()
+	}
+}
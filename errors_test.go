@@ -0,0 +1,189 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	for input, expected := range map[string]error{
+		"@./test/nonexisting.json": ErrFileNotFound,
+		"@./test":                  ErrIsDirectory,
+		"@./test/test.txt":         ErrUnsupportedFormat,
+		"name: John":               ErrUnrecognisedInline,
+	} {
+		_, err := Unmarshal(input)
+		if !errors.Is(err, expected) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+		}
+		err = UnmarshalInto(input, &s{})
+		if !errors.Is(err, expected) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		input  string
+		format Format
+		line   int
+		column int
+	}{
+		{"{\n  \"name\": \"John\",\n  \"age\": x\n}", FormatJSON, 3, 10},
+		{"{\n  \"name\": \"John\"\n  \"age\": 23\n}", FormatJSON, 3, 3},
+		{"---\nname: John\n  surname: Doe\n", FormatYAML, 3, 0},
+		{"toml:name = \"John\"\nage = \n", FormatTOML, 2, 5},
+		{"csv:a,b\n1,\"2\n", FormatCSV, 2, 5},
+		{"<a>\n<b></c>\n</a>", FormatXML, 2, 0},
+		{"properties:a=1\na.b=2", FormatProperties, 2, 0},
+	}
+	for _, test := range tests {
+		_, err := Unmarshal(test.input)
+		var decodeError *DecodeError
+		if !errors.As(err, &decodeError) {
+			t.Errorf("invalid error for %q: %v (type %T)", test.input, err, err)
+			continue
+		}
+		if decodeError.Format() != test.format || decodeError.Line != test.line || decodeError.Column != test.column {
+			t.Errorf("invalid position for %q: expected %v %d:%d, got %v %d:%d (%v)", test.input, test.format, test.line, test.column, decodeError.Format(), decodeError.Line, decodeError.Column, err)
+		}
+	}
+
+	// the underlying library error is still available
+	err := UnmarshalInto(`{"name": 42}`, &s{})
+	var typeError *json.UnmarshalTypeError
+	if !errors.As(err, &typeError) || typeError.Field != "name" {
+		t.Errorf("invalid underlying error: %v", err)
+	}
+	var decodeError *DecodeError
+	if !errors.As(err, &decodeError) || decodeError.Line != 1 || decodeError.Column != 11 {
+		t.Errorf("invalid decode error: %v", err)
+	}
+	if message := err.Error(); message != "error decoding inline value: error unmarshalling from JSON at line 1, column 11: "+typeError.Error() {
+		t.Errorf("invalid message: %s", message)
+	}
+
+	err = UnmarshalInto("---\nname: John\nnickname: Johnny\n", &s{}, WithStrict(true))
+	if !errors.As(err, &decodeError) || decodeError.Line != 3 {
+		t.Errorf("invalid strict YAML error: %v", err)
+	}
+	var yamlError *yaml.TypeError
+	if !errors.As(err, &yamlError) {
+		t.Errorf("invalid underlying YAML error: %v (type %T)", decodeError.Err, decodeError.Err)
+	}
+}
+
+func TestDecodeErrorSource(t *testing.T) {
+	testCases := map[string]string{
+		"@./test/invalid.yaml":          "error decoding './test/invalid.yaml': ",
+		"yaml:@./test/invalid.yaml":     "error decoding './test/invalid.yaml': ",
+		"json:{\"name\": }":             "error decoding inline value: ",
+		`json:\@{"name": 1}`:            "error decoding inline value: ",
+		"data:application/json,%7B%7D]": "error decoding data URI: ",
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("error getting working directory: %v", err)
+	}
+	testCases["file://"+filepath.ToSlash(wd)+"/test/invalid.yaml"] = "error decoding '" + filepath.ToSlash(wd) + "/test/invalid.yaml': "
+	for input, prefix := range testCases {
+		_, err := Unmarshal(input)
+		var decodeError *DecodeError
+		if !errors.As(err, &decodeError) {
+			t.Fatalf("expected decode error for %q, got %v", input, err)
+		}
+		if !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("invalid message for %q: %v", input, err)
+		}
+		if err := UnmarshalInto(input, &map[string]interface{}{}); err == nil || !strings.HasPrefix(err.Error(), prefix) {
+			t.Errorf("invalid message for %q in UnmarshalInto: %v", input, err)
+		}
+	}
+}
+
+func TestDecodeErrorTypeMismatch(t *testing.T) {
+	type target struct {
+		Server struct {
+			Port  int      `json:"port" yaml:"port" toml:"port"`
+			Hosts []string `json:"hosts" yaml:"hosts" toml:"hosts"`
+		} `json:"server" yaml:"server" toml:"server"`
+	}
+	tests := []struct {
+		input    string
+		field    string
+		expected string
+		got      string
+		line     int
+	}{
+		{"{\n  \"server\": {\n    \"port\": \"8080\"\n  }\n}", "server.port", "integer", "string", 3},
+		{"{\"server\": {\"port\": 80.5}}", "server.port", "integer", "float", 1},
+		{"{\"server\": {\"hosts\": [\"a\", 1]}}", "server.hosts.1", "string", "integer", 1},
+		{"{\"server\": 1}", "server", "object", "integer", 1},
+		{"---\nserver:\n  port: eighty\n", "server.port", "integer", "string", 3},
+		{"---\nserver:\n  port: [80]\n", "server.port", "integer", "array", 3},
+		{"---\nserver:\n  hosts: [a, {b: c}]\n", "server.hosts.1", "string", "object", 3},
+		{"---\nserver:\n  port: a-very-long-port-name\n", "server.port", "integer", "string", 3},
+		{"---\nserver: 1\n", "server", "object", "integer", 2},
+		{"toml:[server]\nport = \"8080\"\n", "server.port", "integer", "string", 2},
+		{"toml:[server]\nport = 1.5\n", "server.port", "integer", "float", 2},
+		{"toml:[server]\nhosts = 1\n", "server.hosts", "array", "integer", 2},
+		{"properties:server.port=abc", "server.port", "integer", "string", 0},
+		{"ndjson:{\"server\": {\"port\": true}}", "0.server.port", "integer", "bool", 0},
+	}
+	for _, test := range tests {
+		var into interface{} = &target{}
+		if strings.HasPrefix(test.input, "ndjson:") {
+			into = &[]target{}
+		}
+		err := UnmarshalInto(test.input, into)
+		var decodeError *DecodeError
+		if !errors.As(err, &decodeError) {
+			t.Errorf("invalid error for %q: %v (type %T)", test.input, err, err)
+			continue
+		}
+		if decodeError.Field() != test.field || decodeError.Expected() != test.expected || decodeError.Got() != test.got || decodeError.Line != test.line {
+			t.Errorf("invalid mismatch for %q: expected %s %s %s at line %d, got %s %s %s at line %d", test.input, test.field, test.expected, test.got, test.line, decodeError.Field(), decodeError.Expected(), decodeError.Got(), decodeError.Line)
+		}
+	}
+
+	// CSV rows, and TOML arrays of tables, whose paths have no indexes
+	var rows []struct {
+		Port int `json:"port"`
+	}
+	err := UnmarshalInto("csv:port\nabc\n", &rows)
+	var decodeError *DecodeError
+	if !errors.As(err, &decodeError) || decodeError.Format() != FormatCSV || decodeError.Field() != "0.port" || decodeError.Expected() != "integer" || decodeError.Got() != "string" {
+		t.Errorf("invalid CSV mismatch: %v (%+v)", err, decodeError)
+	}
+	var tables struct {
+		Servers []struct {
+			Port int `toml:"port"`
+		} `toml:"servers"`
+	}
+	err = UnmarshalInto("toml:[[servers]]\nport = 1\n[[servers]]\nport = [2]\n", &tables)
+	if !errors.As(err, &decodeError) || decodeError.Field() != "servers.port" || decodeError.Expected() != "integer" || decodeError.Got() != "array" {
+		t.Errorf("invalid TOML table mismatch: %v (%+v)", err, decodeError)
+	}
+
+	// the underlying library errors are still available
+	var jsonError *json.UnmarshalTypeError
+	if err := UnmarshalInto(`{"server": {"port": "x"}}`, &target{}); !errors.As(err, &jsonError) {
+		t.Errorf("invalid underlying JSON error: %v", err)
+	}
+	var yamlError *yaml.TypeError
+	if err := UnmarshalInto("---\nserver:\n  port: x\n", &target{}); !errors.As(err, &yamlError) {
+		t.Errorf("invalid underlying YAML error: %v", err)
+	}
+
+	// syntax errors are not type mismatches
+	if err := UnmarshalInto(`{"server": }`, &target{}); !errors.As(err, &decodeError) || decodeError.Field() != "" || decodeError.Expected() != "" || decodeError.Got() != "" {
+		t.Errorf("invalid syntax error: %v", err)
+	}
+}
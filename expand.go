@@ -0,0 +1,76 @@
+package rawdata
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// expandEnv replaces references to environment variables in the data with
+// their values: $VAR and ${VAR}, where VAR is made of letters, digits and
+// underscores and does not start with a digit, and ${VAR:-default}, which
+// expands to default when VAR is unset or empty; $$ is an escape for a
+// literal $. Any other $ (e.g. in "a$1b" or "$@") is left untouched, unlike
+// with os.Expand, which would take it for a special shell variable.
+func expandEnv(content []byte) []byte {
+	var result bytes.Buffer
+	result.Grow(len(content))
+	for i := 0; i < len(content); i++ {
+		if content[i] != '$' || i+1 == len(content) {
+			result.WriteByte(content[i])
+			continue
+		}
+		switch next := content[i+1]; {
+		case next == '$':
+			result.WriteByte('$')
+			i++
+		case next == '{':
+			end := bytes.IndexByte(content[i+2:], '}')
+			if end < 0 {
+				result.WriteByte('$')
+				continue
+			}
+			reference := string(content[i+2 : i+2+end])
+			name, fallback, hasDefault := strings.Cut(reference, ":-")
+			if !isIdentifier(name) {
+				result.WriteByte('$')
+				continue
+			}
+			value := os.Getenv(name)
+			if value == "" && hasDefault {
+				value = fallback
+			}
+			result.WriteString(value)
+			i += 2 + end
+		case isIdentifierStart(next):
+			end := i + 2
+			for end < len(content) && (isIdentifierStart(content[end]) || content[end] >= '0' && content[end] <= '9') {
+				end++
+			}
+			result.WriteString(os.Getenv(string(content[i+1 : end])))
+			i = end - 1
+		default:
+			result.WriteByte('$')
+		}
+	}
+	return result.Bytes()
+}
+
+// isIdentifier returns whether the name is a valid environment variable name.
+func isIdentifier(name string) bool {
+	if name == "" || !isIdentifierStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isIdentifierStart(name[i]) && (name[i] < '0' || name[i] > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifierStart returns whether the byte can start an environment
+// variable name.
+func isIdentifierStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
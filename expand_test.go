@@ -0,0 +1,49 @@
+package rawdata
+
+import "testing"
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("RAWDATA_NAME", "John")
+	t.Setenv("RAWDATA_EMPTY", "")
+	for input, expected := range map[string]string{
+		"$RAWDATA_NAME":                       "John",
+		"${RAWDATA_NAME}":                     "John",
+		"${RAWDATA_NAME:-Jane}":               "John",
+		"${RAWDATA_EMPTY:-Jane}":              "Jane",
+		"${RAWDATA_MISSING:-Jane}":            "Jane",
+		"${RAWDATA_MISSING}":                  "",
+		"$$RAWDATA_NAME costs $$5":            "$RAWDATA_NAME costs $5",
+		"${RAWDATA_MISSING:-http://host:80/}": "http://host:80/",
+		"password: a$1b":                      "password: a$1b",
+		"$@ $* $# $? $- $! $0":                "$@ $* $# $? $- $! $0",
+		"${1} ${RAWDATA NAME} ${RAWDATA_NAME": "${1} ${RAWDATA NAME} ${RAWDATA_NAME",
+		"cost: 5$":                            "cost: 5$",
+		"$RAWDATA_NAME-$RAWDATA_NAME.x":       "John-John.x",
+		"${RAWDATA_NAME}s":                    "Johns",
+	} {
+		if actual := string(expandEnv([]byte(input))); actual != expected {
+			t.Errorf("error expanding %q: expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+func TestUnmarshalWithEnvExpansion(t *testing.T) {
+	t.Setenv("RAWDATA_NAME", "John")
+	input := "---\nname: ${RAWDATA_NAME}\nsurname: ${RAWDATA_SURNAME:-Doe}\nage: 23\n"
+
+	result := &s{}
+	if err := UnmarshalInto(input, result, WithEnvExpansion(true)); err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if result.Name != "John" || result.Surname != "Doe" {
+		t.Fatalf("invalid value unmarshalled: %+v", result)
+	}
+
+	result = &s{}
+	if err := UnmarshalInto(input, result); err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if result.Name != "${RAWDATA_NAME}" {
+		t.Fatalf("unexpected expansion without option: %+v", result)
+	}
+}
@@ -0,0 +1,168 @@
+package rawdata
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Flatten turns a value as returned by Unmarshal into a flat map, whose keys
+// are the paths to the leaves of the original value, joined by a separator
+// ("." unless set via WithSeparator), with array elements identified by
+// their index: e.g. {"a": {"b": [1, 2]}} becomes {"a.b.0": 1, "a.b.1": 2}.
+// Empty objects and arrays are kept as leaves, so that Unflatten can restore
+// them. The value must be an object or an array, and no key can contain the
+// separator, since the resulting path would be ambiguous.
+func Flatten(v interface{}, opts ...Option) (map[string]interface{}, error) {
+	o := newOptions(opts...)
+	if !IsObject(v) && !IsArray(v) {
+		return nil, fmt.Errorf("cannot flatten %s, only objects and arrays", kindOf(v))
+	}
+	result := map[string]interface{}{}
+	if err := flatten(v, "", o.separator, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// flatten adds the leaves of the given value to the result, with their keys
+// prefixed by the path to the value.
+func flatten(v interface{}, path string, separator string, result map[string]interface{}) error {
+	switch value := v.(type) {
+	case *OrderedMap:
+		if value.Len() == 0 {
+			break
+		}
+		var err error
+		value.Range(func(key string, item interface{}) bool {
+			err = flattenEntry(key, item, path, separator, result)
+			return err == nil
+		})
+		return err
+	case map[string]interface{}:
+		if len(value) == 0 {
+			break
+		}
+		for key, item := range value {
+			if err := flattenEntry(key, item, path, separator, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if len(value) == 0 {
+			break
+		}
+		for i, item := range value {
+			if err := flatten(item, joinPath(path, strconv.Itoa(i), separator), separator, result); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	result[path] = v
+	return nil
+}
+
+// flattenEntry flattens an entry of an object, checking that its key does
+// not contain the separator.
+func flattenEntry(key string, item interface{}, path string, separator string, result map[string]interface{}) error {
+	if strings.Contains(key, separator) {
+		if path == "" {
+			return fmt.Errorf("cannot flatten key '%s': it contains the separator '%s'", key, separator)
+		}
+		return fmt.Errorf("cannot flatten key '%s' at '%s': it contains the separator '%s'", key, path, separator)
+	}
+	return flatten(item, joinPath(path, key, separator), separator, result)
+}
+
+// joinPath appends the key to the path, using the given separator.
+func joinPath(path string, key string, separator string) string {
+	if path == "" {
+		return key
+	}
+	return path + separator + key
+}
+
+// Unflatten is the inverse of Flatten: it rebuilds nested objects from the
+// paths in the keys of the given map, split on the given separator ("." if
+// empty); objects whose keys are exactly the indexes 0 to n-1 (e.g. from
+// "a.0" and "a.1") become arrays. A key that is both a leaf and a prefix of
+// another key (e.g. "a" and "a.b") is an error.
+func Unflatten(flat map[string]interface{}, separator string) (map[string]interface{}, error) {
+	if separator == "" {
+		separator = "."
+	}
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	// sorting makes conflicts always reported in the same way
+	sort.Strings(keys)
+	root := branch{}
+	for _, key := range keys {
+		segments := strings.Split(key, separator)
+		current := root
+		for i, segment := range segments[:len(segments)-1] {
+			switch child := current[segment].(type) {
+			case nil:
+				if _, ok := current[segment]; ok {
+					return nil, fmt.Errorf("cannot unflatten key '%s': '%s' is a leaf", key, strings.Join(segments[:i+1], separator))
+				}
+				next := branch{}
+				current[segment] = next
+				current = next
+			case branch:
+				current = child
+			default:
+				return nil, fmt.Errorf("cannot unflatten key '%s': '%s' is a leaf", key, strings.Join(segments[:i+1], separator))
+			}
+		}
+		last := segments[len(segments)-1]
+		if _, ok := current[last]; ok {
+			return nil, fmt.Errorf("cannot unflatten key '%s': it is a prefix of other keys", key)
+		}
+		current[last] = flat[key]
+	}
+	result := make(map[string]interface{}, len(root))
+	for key, value := range root {
+		result[key] = unflattenValue(value)
+	}
+	return result, nil
+}
+
+// branch is an object being rebuilt by Unflatten, as opposed to a leaf value
+// which happens to be an object.
+type branch map[string]interface{}
+
+// unflattenValue turns the branches in the given value into objects, or into
+// arrays if their keys are all indexes.
+func unflattenValue(v interface{}) interface{} {
+	b, ok := v.(branch)
+	if !ok {
+		return v
+	}
+	if array, ok := indexedArray(b); ok {
+		return array
+	}
+	object := make(map[string]interface{}, len(b))
+	for key, value := range b {
+		object[key] = unflattenValue(value)
+	}
+	return object
+}
+
+// indexedArray returns the branch as an array, if its keys are exactly the
+// indexes from 0 to n-1, in canonical form (e.g. "1" but not "01").
+func indexedArray(b branch) ([]interface{}, bool) {
+	array := make([]interface{}, len(b))
+	for key, value := range b {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(b) || strconv.Itoa(index) != key {
+			return nil, false
+		}
+		array[index] = unflattenValue(value)
+	}
+	return array, true
+}
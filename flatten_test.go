@@ -0,0 +1,109 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	value, err := Unmarshal(`{"a": {"b": 1, "c": [true, {"d": "x"}]}, "e": {}, "f": [], "g": null}`)
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	flat, err := Flatten(value)
+	if err != nil {
+		t.Fatalf("error flattening: %v", err)
+	}
+	expected := map[string]interface{}{
+		"a.b":     float64(1),
+		"a.c.0":   true,
+		"a.c.1.d": "x",
+		"e":       map[string]interface{}{},
+		"f":       []interface{}{},
+		"g":       nil,
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("invalid result: expected %v, got %v", expected, flat)
+	}
+	ordered, err := Unmarshal(`{"a": {"b": 1}}`, WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if flat, err = Flatten(ordered, WithSeparator("/")); err != nil {
+		t.Fatalf("error flattening ordered map: %v", err)
+	}
+	if !reflect.DeepEqual(flat, map[string]interface{}{"a/b": float64(1)}) {
+		t.Errorf("invalid result with separator: %v", flat)
+	}
+	if flat, err = Flatten([]interface{}{"x", []interface{}{"y"}}, WithSeparator("_")); err != nil {
+		t.Fatalf("error flattening array: %v", err)
+	}
+	if !reflect.DeepEqual(flat, map[string]interface{}{"0": "x", "1_0": "y"}) {
+		t.Errorf("invalid result for array: %v", flat)
+	}
+	for _, v := range []interface{}{
+		nil,
+		42,
+		map[string]interface{}{"a.b": 1},
+		map[string]interface{}{"a": map[string]interface{}{"b.c": 1}},
+	} {
+		if _, err := Flatten(v); err == nil {
+			t.Errorf("expected error flattening %v", v)
+		}
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	flat := map[string]interface{}{
+		"a.b":     float64(1),
+		"a.c.0":   true,
+		"a.c.1.d": "x",
+		"e":       map[string]interface{}{},
+		"f":       []interface{}{},
+		"g":       nil,
+		"h.0":     "sparse",
+		"h.2":     "keys",
+		"i.01":    "not an index",
+	}
+	result, err := Unflatten(flat, "")
+	if err != nil {
+		t.Fatalf("error unflattening: %v", err)
+	}
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": float64(1),
+			"c": []interface{}{true, map[string]interface{}{"d": "x"}},
+		},
+		"e": map[string]interface{}{},
+		"f": []interface{}{},
+		"g": nil,
+		"h": map[string]interface{}{"0": "sparse", "2": "keys"},
+		"i": map[string]interface{}{"01": "not an index"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: expected %v, got %v", expected, result)
+	}
+	// round trip
+	value, err := Unmarshal("---\nserver:\n  port: 8080\n  hosts: [a, b]\n")
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if flat, err = Flatten(value, WithSeparator("_")); err != nil {
+		t.Fatalf("error flattening: %v", err)
+	}
+	if result, err = Unflatten(flat, "_"); err != nil {
+		t.Fatalf("error unflattening: %v", err)
+	}
+	if !reflect.DeepEqual(result, value) {
+		t.Errorf("round trip failed: expected %v, got %v", value, result)
+	}
+	for _, flat := range []map[string]interface{}{
+		{"a": 1, "a.b": 2},
+		{"a": nil, "a.b.c": 2},
+		{"a": map[string]interface{}{}, "a.b": 2},
+	} {
+		if _, err := Unflatten(flat, "."); err == nil {
+			t.Errorf("expected conflict unflattening %v", flat)
+		}
+	}
+}
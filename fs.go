@@ -0,0 +1,29 @@
+package rawdata
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// osFS is an fs.FS backed by the local filesystem; unlike os.DirFS, it
+// accepts any path the os package accepts (absolute, relative to the current
+// directory, with "." and ".." elements) so that file references keep the
+// same semantics they would have on the command line.
+type osFS struct{}
+
+// Open opens the named file on the local filesystem.
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+// Stat returns information about the named file on the local filesystem.
+func (osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// Glob returns the names of the files on the local filesystem matching the
+// given pattern.
+func (osFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
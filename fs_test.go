@@ -0,0 +1,50 @@
+package rawdata
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUnmarshalWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/app.json":  &fstest.MapFile{Data: []byte(`{"name": "John", "surname": "Doe", "age": 23}`)},
+		"config/app.yaml":  &fstest.MapFile{Data: []byte("name: Jane\nsurname: Doe\nage: 32\n")},
+		"config/tls.yaml":  &fstest.MapFile{Data: []byte("cert: /etc/cert.pem\n")},
+		"config/main.yaml": &fstest.MapFile{Data: []byte("tls: '@tls.yaml'\n")},
+	}
+
+	result := &s{}
+	if err := UnmarshalInto("@./config/app.json", result, WithFS(fsys)); err != nil {
+		t.Fatalf("error unmarshalling from fs.FS: %v", err)
+	}
+	if result.Name != "John" || result.Age != 23 {
+		t.Fatalf("invalid value unmarshalled from fs.FS: %+v", result)
+	}
+
+	if err := UnmarshalInto("@app.yaml", result, WithFS(fsys), WithBaseDir("/config")); err != nil {
+		t.Fatalf("error unmarshalling from fs.FS: %v", err)
+	}
+	if result.Name != "Jane" || result.Age != 32 {
+		t.Fatalf("invalid value unmarshalled from fs.FS: %+v", result)
+	}
+
+	array, err := Unmarshal("@config/*.json", WithFS(fsys))
+	if err != nil {
+		t.Fatalf("error unmarshalling glob from fs.FS: %v", err)
+	}
+	if array, ok := array.([]interface{}); !ok || len(array) != 1 {
+		t.Fatalf("invalid output: %v (type %T)", array, array)
+	}
+
+	if _, err := Unmarshal("@config/main.yaml", WithFS(fsys), WithIncludes(true)); err != nil {
+		t.Fatalf("error unmarshalling with includes from fs.FS: %v", err)
+	}
+
+	if _, err := Unmarshal("@./test/struct.json", WithFS(fsys)); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("invalid error for file not in fs.FS: %v", err)
+	}
+	if _, err := Unmarshal("@config", WithFS(fsys)); !errors.Is(err, ErrIsDirectory) {
+		t.Fatalf("invalid error for directory in fs.FS: %v", err)
+	}
+}
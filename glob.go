@@ -0,0 +1,185 @@
+package rawdata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// isGlob returns whether the file reference is a glob pattern; a path that
+// contains pattern metacharacters but exists as a file is not considered a
+// pattern, so that such files can still be referenced directly.
+func isGlob(filename string, o *options) bool {
+	if !strings.ContainsAny(filename, "*?[") {
+		return false
+	}
+	_, err := fs.Stat(o.filesystem(), filename)
+	return err != nil
+}
+
+// loadGlob expands the given glob pattern and reads all the matching files,
+// in sorted filename order; all the files must have the same format, and
+// their contents are combined into a single document representing an array
+// whose elements are the individual documents, so that e.g. Unmarshal
+// returns an []interface{} with one element per file. A "**" path segment
+// matches any number of directories (e.g. "configs/**/*.yaml"), see
+// globFiles. TOML does not support top-level arrays, so it cannot be used
+// with glob patterns. A pattern matching no files is an error, unless empty
+// globs are allowed (see WithAllowEmptyGlob), in which case the result is an
+// empty array.
+func loadGlob(pattern string, forced Format, o *options) (Format, []byte, error) {
+	filenames, err := globFiles(pattern, o)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("invalid glob pattern '%s': %w", pattern, err)
+	}
+	if len(filenames) == 0 && !o.allowEmptyGlob {
+		return FormatUnknown, nil, fmt.Errorf("no files matching pattern '%s': %w", pattern, ErrFileNotFound)
+	}
+	sort.Strings(filenames)
+	format := forced
+	if len(filenames) == 0 && format == FormatUnknown {
+		format = FormatJSON
+	}
+	contents := make([][]byte, 0, len(filenames))
+	for _, filename := range filenames {
+		detected, content, err := loadFile(filename, forced, o)
+		if err != nil {
+			return FormatUnknown, nil, err
+		}
+		if isEmpty(content) {
+			if !o.allowEmpty {
+				return FormatUnknown, nil, fmt.Errorf("no data in '%s': %w", filename, ErrEmptyContent)
+			}
+			// empty files become null elements
+			content = []byte("null")
+		}
+		if forced != FormatUnknown {
+			detected = forced
+		} else if detected == FormatUnknown {
+			if detected, err = sniffFormat(content, o); err != nil {
+				return FormatUnknown, nil, fmt.Errorf("error detecting format of file '%s': %w", filename, err)
+			}
+		}
+		if format == FormatUnknown {
+			format = detected
+		} else if detected != format {
+			return FormatUnknown, nil, fmt.Errorf("file '%s' has format %v, expected %v: all files matching '%s' must have the same format", filename, detected, format, pattern)
+		}
+		contents = append(contents, content)
+	}
+	switch format {
+	case FormatJSON:
+		return format, combineJSON(contents), nil
+	case FormatYAML:
+		content, err := combineYAML(contents)
+		return format, content, err
+	default:
+		return FormatUnknown, nil, fmt.Errorf("%w for glob pattern '%s': %v", ErrUnsupportedFormat, pattern, format)
+	}
+}
+
+// globFiles returns the names of the files matching the given pattern; if
+// any of its path segments is "**", the directory tree under the segments
+// before the first pattern is walked and the files are matched segment by
+// segment, with "**" matching zero or more directories, otherwise the pattern
+// is expanded by fs.Glob.
+func globFiles(pattern string, o *options) ([]string, error) {
+	segments := strings.Split(path.Clean(filepath.ToSlash(pattern)), "/")
+	recursive := false
+	for _, segment := range segments {
+		if segment == "**" {
+			recursive = true
+		} else if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+	if !recursive {
+		return fs.Glob(o.filesystem(), pattern)
+	}
+	static := 0
+	for static < len(segments) && !strings.ContainsAny(segments[static], "*?[") {
+		static++
+	}
+	root := strings.Join(segments[:static], "/")
+	switch {
+	case root == "" && static > 0:
+		// an absolute path, only the leading slash is static
+		root = "/"
+	case root == "":
+		root = "."
+	}
+	filenames := []string{}
+	err := fs.WalkDir(o.filesystem(), root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == root {
+				// no files at all
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !entry.IsDir() && matchSegments(segments, strings.Split(name, "/")) {
+			filenames = append(filenames, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filenames, nil
+}
+
+// matchSegments returns whether the path segments of a name match those of a
+// pattern, where "**" matches any number of segments, and any other pattern
+// segment matches a single one as in path.Match.
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		return matchSegments(pattern[1:], name) || (len(name) > 0 && matchSegments(pattern, name[1:]))
+	}
+	if len(name) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], name[0])
+	return matched && matchSegments(pattern[1:], name[1:])
+}
+
+// combineJSON combines several JSON documents into a JSON array.
+func combineJSON(contents [][]byte) []byte {
+	for i, content := range contents {
+		contents[i] = bytes.TrimSpace(content)
+	}
+	// newlines keep a trailing line comment (see WithAllowComments) in one
+	// file from swallowing the next one
+	result := append([]byte("[\n"), bytes.Join(contents, []byte("\n,\n"))...)
+	return append(result, '\n', ']')
+}
+
+// combineYAML combines several YAML documents into a YAML sequence.
+func combineYAML(contents [][]byte) ([]byte, error) {
+	sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, content := range contents {
+		document := yaml.Node{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, newDecodeError(FormatYAML, content, err)
+		}
+		if len(document.Content) == 0 {
+			sequence.Content = append(sequence.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+		} else {
+			sequence.Content = append(sequence.Content, document.Content[0])
+		}
+	}
+	content, err := yaml.Marshal(sequence)
+	if err != nil {
+		return nil, fmt.Errorf("error combining YAML documents: %w", err)
+	}
+	return content, nil
+}
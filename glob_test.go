@@ -0,0 +1,122 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+type rule struct {
+	Name     string `json:"name" yaml:"name"`
+	Priority int    `json:"priority" yaml:"priority"`
+}
+
+func TestUnmarshalGlob(t *testing.T) {
+	result, err := Unmarshal("@./test/rules/*.y*ml")
+	if err != nil {
+		t.Fatalf("error unmarshalling glob: %v", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"name": "first", "priority": 1},
+		map[string]interface{}{"name": "second", "priority": 2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("error unmarshalling glob: expected %v, got %v", expected, result)
+	}
+
+	rules := []rule{}
+	if err := UnmarshalInto("@./test/rules/*.y*ml", &rules); err != nil {
+		t.Fatalf("error unmarshalling glob: %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name != "first" || rules[1].Priority != 2 {
+		t.Fatalf("invalid value unmarshalled from glob: %+v", rules)
+	}
+
+	result, err = Unmarshal("@./test/[as]*.json")
+	if err != nil {
+		t.Fatalf("error unmarshalling glob: %v", err)
+	}
+	if result, ok := result.([]interface{}); !ok || len(result) != 2 {
+		t.Fatalf("invalid output: %v (type %T)", result, result)
+	}
+}
+
+func TestUnmarshalGlobErrors(t *testing.T) {
+	for _, input := range []string{
+		"@./test/rules/*",      // mixed formats
+		"@./test/rules/*.xml",  // no matches
+		"@./test/*.toml",       // no top-level arrays in TOML
+		"@./test/invalid.js*n", // invalid contents
+		"@./test/rules/[.yaml", // invalid pattern
+	} {
+		if _, err := Unmarshal(input); err == nil {
+			t.Errorf("no error unmarshalling glob %q", input)
+		}
+	}
+}
+
+func TestUnmarshalRecursiveGlob(t *testing.T) {
+	testCases := map[string][]string{
+		"@./test/tree/**/*.yaml":         {"root", "cache", "db", "replica"},
+		"@test/tree/db/**/*.yaml":        {"db", "replica"},
+		"@./test/tree/**/replica/*.yaml": {"replica"},
+		"@./test/**/db/*.yaml":           {"db"},
+		"@./test/tree/**":                nil, // README.md has no supported format
+	}
+	for input, expected := range testCases {
+		rules := []rule{}
+		err := UnmarshalInto(input, &rules)
+		if expected == nil {
+			if err == nil {
+				t.Errorf("no error unmarshalling glob %q", input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("error unmarshalling glob %q: %v", input, err)
+		}
+		names := []string{}
+		for _, r := range rules {
+			names = append(names, r.Name)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("invalid files matched by %q: expected %v, got %v", input, expected, names)
+		}
+	}
+	fsys := fstest.MapFS{
+		"conf/a.json":      {Data: []byte(`{"name": "a"}`)},
+		"conf/x/y/b.json":  {Data: []byte(`{"name": "b"}`)},
+		"conf/x/y/c.yaml":  {Data: []byte("name: c\n")},
+		"other/x/y/d.json": {Data: []byte(`{"name": "d"}`)},
+	}
+	result, err := Unmarshal("@conf/**/*.json", WithFS(fsys))
+	if err != nil {
+		t.Fatalf("error unmarshalling recursive glob from fs.FS: %v", err)
+	}
+	if !reflect.DeepEqual(result, []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}) {
+		t.Errorf("invalid result from fs.FS: %v", result)
+	}
+}
+
+func TestUnmarshalEmptyGlob(t *testing.T) {
+	for _, input := range []string{"@./test/rules/*.xml", "@./test/missing/**/*.yaml"} {
+		if _, err := Unmarshal(input); !errors.Is(err, ErrFileNotFound) {
+			t.Errorf("expected ErrFileNotFound for %q, got %v", input, err)
+		}
+		result, err := Unmarshal(input, WithAllowEmptyGlob(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling empty glob %q: %v", input, err)
+		}
+		if !reflect.DeepEqual(result, []interface{}{}) {
+			t.Errorf("invalid result for empty glob %q: %v", input, result)
+		}
+	}
+	rules := []rule{{Name: "previous"}}
+	if err := UnmarshalInto("yaml:@./test/rules/*.xml", &rules, WithAllowEmptyGlob(true)); err != nil || len(rules) != 0 {
+		t.Errorf("invalid result for empty YAML glob: %v (error: %v)", rules, err)
+	}
+	if _, err := Unmarshal("@./test/tree/**/[.yaml"); err == nil {
+		t.Error("no error unmarshalling invalid recursive glob")
+	}
+}
@@ -1,5 +1,11 @@
 module github.com/dihedron/rawdata
 
-go 1.17
+go 1.18
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.0
+	github.com/spf13/afero v1.10.0
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
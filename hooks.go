@@ -0,0 +1,191 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// applyDecodeHooks runs the hooks set via WithDecodeHook on the values in the
+// data, along with the types of the fields in the target they are bound for,
+// and returns the result, encoded in the format it must be decoded from into
+// the target (see applyDefaults); the data is returned untouched if no hook
+// changed anything, so that errors still report positions in the original.
+func applyDecodeHooks(format Format, content []byte, target interface{}, o *options) (Format, []byte, error) {
+	encoding, tag := format, ""
+	switch format {
+	case FormatJSON:
+		tag = "json"
+	case FormatYAML:
+		tag = "yaml"
+	case FormatTOML:
+		tag = "toml"
+	case FormatCSV, FormatProperties, FormatNDJSON:
+		encoding, tag = FormatJSON, "json"
+	default:
+		return format, content, nil
+	}
+	if isEmpty(content) {
+		return format, content, nil
+	}
+	// hooks get plain maps, and JSON numbers must survive the round trip;
+	// YAML data is normalised after the hooks, when it is decoded
+	generic := *o
+	generic.orderedMaps = false
+	generic.numberMode = NumberModeDefault
+	generic.useNumber = format == FormatJSON
+	generic.timeLayouts = nil
+	generic.yamlBoolCompat = false
+	data, err := decode(format, content, &generic)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	walker := hookWalker{format: format, fields: unknownKeyWalker{tag: tag}, hooks: o.decodeHooks}
+	if data, err = walker.walk(data, reflect.TypeOf(target), ""); err != nil {
+		return FormatUnknown, nil, err
+	}
+	if !walker.changed {
+		return format, content, nil
+	}
+	if content, err = Marshal(data, encoding); err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error encoding converted values: %w", err)
+	}
+	return encoding, content, nil
+}
+
+// hookWalker walks a generic value along with the type that it is going to
+// be decoded into, running the decode hooks on it.
+type hookWalker struct {
+	format Format
+	// fields matches keys to fields, as the library decoding the data does.
+	fields  unknownKeyWalker
+	hooks   []func(from, to reflect.Type, data interface{}) (interface{}, error)
+	changed bool
+}
+
+// walk runs the hooks on the value, which is bound for a value of the given
+// type and is found at the given path, and then on its contents; it returns
+// the converted value.
+func (w *hookWalker) walk(value interface{}, t reflect.Type, path string) (interface{}, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return value, nil
+	}
+	for _, hook := range w.hooks {
+		if value == nil {
+			return nil, nil
+		}
+		converted, err := hook(reflect.TypeOf(value), t, value)
+		if err != nil {
+			return nil, &DecodeError{DataFormat: w.format, Err: err, field: path, expected: typeName(t), got: genericType(value)}
+		}
+		if !sameValue(converted, value) {
+			w.changed = true
+		}
+		value = converted
+	}
+	for _, unmarshaler := range unmarshalerInterfaces {
+		if reflect.PtrTo(t).Implements(unmarshaler) {
+			return value, nil
+		}
+	}
+	var err error
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			var field reflect.Type
+			switch t.Kind() {
+			case reflect.Struct:
+				field, _ = w.fields.lookup(w.fields.fields(t), key)
+			case reflect.Map:
+				field = t.Elem()
+			case reflect.Interface:
+				field = t
+			}
+			if v[key], err = w.walk(item, field, joinPath(path, key, ".")); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if v[i], err = w.walk(item, itemType(t), joinPath(path, strconv.Itoa(i), ".")); err != nil {
+				return nil, err
+			}
+		}
+	case []map[string]interface{}:
+		// arrays of tables, in TOML, which hooks may turn into anything
+		items, tables := make([]interface{}, len(v)), true
+		for i, item := range v {
+			if items[i], err = w.walk(item, itemType(t), joinPath(path, strconv.Itoa(i), ".")); err != nil {
+				return nil, err
+			}
+			if _, ok := items[i].(map[string]interface{}); !ok {
+				tables = false
+			}
+		}
+		if !tables {
+			return items, nil
+		}
+		for i := range v {
+			v[i] = items[i].(map[string]interface{})
+		}
+	}
+	return value, nil
+}
+
+// itemType returns the type of the items of arrays bound for the given
+// type, or nil if they are not bound for anything.
+func itemType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem()
+	case reflect.Interface:
+		return t
+	}
+	return nil
+}
+
+// sameValue returns whether a hook returned the value it was passed.
+func sameValue(a, b interface{}) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if a == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(a); v.Kind() {
+	case reflect.Map, reflect.Slice:
+		return v.Pointer() == reflect.ValueOf(b).Pointer() && v.Len() == reflect.ValueOf(b).Len()
+	default:
+		if !v.Type().Comparable() {
+			return false
+		}
+		return a == b
+	}
+}
+
+// genericType returns the name of the type of a generic value, as used in
+// DecodeErrors.
+func genericType(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case json.Number:
+		return jsonValueType("number " + v.String())
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "float"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}, []map[string]interface{}:
+		return "array"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
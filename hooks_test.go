@@ -0,0 +1,101 @@
+package rawdata
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func stringToDuration(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	return time.ParseDuration(data.(string))
+}
+
+func stringToIP(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(net.IP{}) {
+		return data, nil
+	}
+	ip := net.ParseIP(data.(string))
+	if ip == nil {
+		return nil, errors.New("invalid IP address")
+	}
+	return ip, nil
+}
+
+func TestWithDecodeHook(t *testing.T) {
+	type server struct {
+		Address net.IP         `json:"address" yaml:"address" toml:"address"`
+		Timeout time.Duration  `json:"timeout" yaml:"timeout" toml:"timeout"`
+		Retries *time.Duration `json:"retries" yaml:"retries" toml:"retries"`
+	}
+	type config struct {
+		Name    string   `json:"name" yaml:"name" toml:"name"`
+		Servers []server `json:"servers" yaml:"servers" toml:"servers"`
+	}
+	inputs := []string{
+		`{"name": "app", "servers": [{"address": "10.0.0.1", "timeout": "1m30s", "retries": "2s"}]}`,
+		"---\nname: app\nservers:\n  - address: 10.0.0.1\n    timeout: 1m30s\n    retries: 2s\n",
+		"toml:name = \"app\"\n[[servers]]\naddress = \"10.0.0.1\"\ntimeout = \"1m30s\"\nretries = \"2s\"\n",
+	}
+	for _, input := range inputs {
+		c := config{}
+		if err := UnmarshalInto(input, &c, WithDecodeHook(stringToDuration), WithDecodeHook(stringToIP)); err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if c.Name != "app" || len(c.Servers) != 1 || !c.Servers[0].Address.Equal(net.ParseIP("10.0.0.1")) ||
+			c.Servers[0].Timeout != 90*time.Second || c.Servers[0].Retries == nil || *c.Servers[0].Retries != 2*time.Second {
+			t.Errorf("invalid result for %q: %+v", input, c)
+		}
+	}
+
+	// hooks are called in order, each with the value returned by the previous
+	var calls []string
+	upper := func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		calls = append(calls, "upper")
+		if s, ok := data.(string); ok && to.Kind() == reflect.String {
+			return strings.ToUpper(s), nil
+		}
+		return data, nil
+	}
+	suffix := func(from, to reflect.Type, data interface{}) (interface{}, error) {
+		calls = append(calls, "suffix")
+		if s, ok := data.(string); ok && to.Kind() == reflect.String {
+			return s + "-x", nil
+		}
+		return data, nil
+	}
+	c := config{}
+	if err := UnmarshalInto(`{"name": "app"}`, &c, WithDecodeHook(upper), WithDecodeHook(suffix)); err != nil {
+		t.Fatalf("error unmarshalling with composed hooks: %v", err)
+	}
+	if c.Name != "APP-x" || !reflect.DeepEqual(calls, []string{"upper", "suffix", "upper", "suffix"}) {
+		t.Errorf("invalid composition: %q, calls %v", c.Name, calls)
+	}
+
+	// hooks also apply to the formats decoded via JSON
+	s := []server{}
+	if err := UnmarshalInto("csv:address,timeout\n10.0.0.2,5s\n", &s, WithDecodeHook(stringToDuration)); err != nil {
+		t.Fatalf("error unmarshalling CSV: %v", err)
+	}
+	if len(s) != 1 || s[0].Timeout != 5*time.Second || !s[0].Address.Equal(net.ParseIP("10.0.0.2")) {
+		t.Errorf("invalid CSV result: %+v", s)
+	}
+
+	// errors from hooks report the field
+	err := UnmarshalInto(inputs[1]+"  - address: nowhere\n", &c, WithDecodeHook(stringToIP))
+	var decodeError *DecodeError
+	if !errors.As(err, &decodeError) || decodeError.Field() != "servers.1.address" || decodeError.Expected() != "array" || decodeError.Got() != "string" {
+		t.Errorf("invalid hook error: %v", err)
+	}
+
+	// data that no hook converts is decoded as is, with positions intact
+	err = UnmarshalInto("{\n  \"name\": 42\n}", &c, WithDecodeHook(stringToDuration))
+	if !errors.As(err, &decodeError) || decodeError.Line != 2 {
+		t.Errorf("invalid error for unconverted data: %v", err)
+	}
+}
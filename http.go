@@ -0,0 +1,92 @@
+package rawdata
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// HTTPClient is the client used to fetch remote documents when the input
+// value is an http:// or https:// URL; it can be replaced to customise
+// timeouts, proxies, TLS configuration and so on, or overridden for single
+// calls via WithHTTPClient.
+var HTTPClient = http.DefaultClient
+
+// fetchContent retrieves a remote document via an HTTP GET; the format is
+// detected first from the Content-Type response header and then from the
+// extension in the URL path; if neither is conclusive, FormatUnknown is
+// returned and the caller should detect it from the data.
+func fetchContent(value string, o *options) (Format, []byte, error) {
+	format, body, err := openRemote(value, o)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	defer body.Close()
+	content, err := readAll(contextReader{o.context(), body}, o.maxFileSize)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error reading response body from '%s': %w", value, err)
+	}
+	return format, content, nil
+}
+
+// openRemote sends an HTTP GET for a remote document and returns its format,
+// detected as in fetchContent, and the response body, which the caller must
+// close.
+func openRemote(value string, o *options) (Format, io.ReadCloser, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("invalid URL '%s': %w", value, err)
+	}
+	request, err := http.NewRequestWithContext(o.context(), http.MethodGet, value, nil)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("invalid URL '%s': %w", value, err)
+	}
+	client := HTTPClient
+	if o.httpClient != nil {
+		client = o.httpClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error fetching '%s': %w", value, err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		response.Body.Close()
+		return FormatUnknown, nil, fmt.Errorf("error fetching '%s': unexpected status code %d (%s)", value, response.StatusCode, http.StatusText(response.StatusCode))
+	}
+	format := formatFromContentType(response.Header.Get("Content-Type"))
+	if format == FormatUnknown {
+		format = formatFromExtension(path.Ext(u.Path), o)
+	}
+	return format, response.Body, nil
+}
+
+// formatFromContentType returns the format associated with the given MIME
+// type, or FormatUnknown if the type is missing or not supported.
+func formatFromContentType(contentType string) Format {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return FormatUnknown
+	}
+	switch strings.ToLower(mediaType) {
+	case "application/json", "text/json":
+		return FormatJSON
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML
+	case "application/toml", "text/toml":
+		return FormatTOML
+	case "text/csv":
+		return FormatCSV
+	case "text/x-java-properties":
+		return FormatProperties
+	case "application/xml", "text/xml":
+		return FormatXML
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON
+	default:
+		return FormatUnknown
+	}
+}
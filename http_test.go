@@ -0,0 +1,47 @@
+package rawdata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnmarshalFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/struct":
+			w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+			w.Write([]byte("name: John\nsurname: Doe\nage: 23\n"))
+		case "/array.json":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte(`["one", "two", "three"]`))
+		case "/sniffed":
+			w.Write([]byte(`{"name": "John", "surname": "Doe", "age": 23}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/struct", "/sniffed"} {
+		result := &s{}
+		if err := UnmarshalInto(server.URL+path, result); err != nil {
+			t.Fatalf("error unmarshalling from URL: %v", err)
+		}
+		if result.Name != "John" || result.Surname != "Doe" || result.Age != 23 {
+			t.Fatalf("invalid value unmarshalled from URL: %+v", result)
+		}
+	}
+
+	result, err := Unmarshal(server.URL + "/array.json")
+	if err != nil {
+		t.Fatalf("error unmarshalling from URL: %v", err)
+	}
+	if result, ok := result.([]interface{}); !ok || len(result) != 3 {
+		t.Fatalf("invalid output: %v (type %T)", result, result)
+	}
+
+	if _, err := Unmarshal(server.URL + "/missing.json"); err == nil {
+		t.Fatal("no error on non-2xx response")
+	}
+}
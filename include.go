@@ -0,0 +1,102 @@
+package rawdata
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// resolveIncludes replaces all the string values starting with '@' in the
+// data unmarshalled from the given value with the unmarshalled contents of
+// the files they refer to, recursively; escaped values lose their escape.
+func resolveIncludes(value string, result interface{}, o *options) (interface{}, error) {
+	dir := "."
+	chain := []string{}
+	if _, value = cutFormatPrefix(value); isSource(value) {
+		chain = append(chain, value)
+	} else if strings.HasPrefix(value, "@") && value != "@-" {
+		filename := strings.TrimPrefix(value, "@")
+		dir = filepath.Dir(filename)
+		if abs, err := filepath.Abs(resolvePath(filename, o)); err == nil {
+			chain = append(chain, abs)
+		}
+	}
+	return expandIncludes(result, dir, chain, 0, o)
+}
+
+// expandIncludes walks the data and replaces the include references it finds;
+// references are resolved relative to dir, chain is the list of files currently
+// being included, used to detect cycles, and depth is the current nesting level.
+func expandIncludes(v interface{}, dir string, chain []string, depth int, o *options) (interface{}, error) {
+	var err error
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if v[key], err = expandIncludes(value, dir, chain, depth, o); err != nil {
+				return nil, err
+			}
+		}
+	case *OrderedMap:
+		for _, key := range v.keys {
+			value, err := expandIncludes(v.values[key], dir, chain, depth, o)
+			if err != nil {
+				return nil, err
+			}
+			v.values[key] = value
+		}
+	case []interface{}:
+		for i, value := range v {
+			if v[i], err = expandIncludes(value, dir, chain, depth, o); err != nil {
+				return nil, err
+			}
+		}
+	case string:
+		if literal, escaped := cutEscape(v); escaped {
+			// an escaped "@" is a literal value, not an include
+			return literal, nil
+		}
+		if !strings.HasPrefix(v, "@") || v == "@-" {
+			break
+		}
+		// in-memory sources are not files, their names are never resolved
+		// against the directory, and they are identified by the reference
+		reference, key, next := v, v, dir
+		if !isSource(v) {
+			// only the path of an archive is resolved, not the name of the
+			// member, which cleaning the path would merge into it
+			filename, member := strings.TrimPrefix(v, "@"), ""
+			if archive, name, ok := cutArchiveMember(v); ok {
+				filename, member = archive, archiveSeparator+name
+			}
+			if o.fsys == nil {
+				filename = expandHome(filename)
+			}
+			if !filepath.IsAbs(filename) {
+				filename = filepath.Join(dir, filename)
+			}
+			abs, err := filepath.Abs(resolvePath(filename, o))
+			if err != nil {
+				return nil, fmt.Errorf("error resolving include '%s': %w", v, err)
+			}
+			reference, key, next = "@"+filename+member, abs+member, filepath.Dir(filename)
+		}
+		for _, included := range chain {
+			if included == key {
+				return nil, fmt.Errorf("cyclic include of '%s' (%s)", v, strings.Join(append(chain, key), " -> "))
+			}
+		}
+		if o.maxIncludeDepth > 0 && depth >= o.maxIncludeDepth {
+			return nil, fmt.Errorf("error including '%s': maximum include depth of %d exceeded", v, o.maxIncludeDepth)
+		}
+		format, content, err := readContent(reference, o)
+		if err != nil {
+			return nil, fmt.Errorf("error including '%s': %w", v, err)
+		}
+		result, err := decode(format, content, o)
+		if err != nil {
+			return nil, fmt.Errorf("error including '%s': %w", v, err)
+		}
+		return expandIncludes(result, next, append(chain[:len(chain):len(chain)], key), depth+1, o)
+	}
+	return v, nil
+}
@@ -0,0 +1,107 @@
+package rawdata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWithIncludes(t *testing.T) {
+	result, err := Unmarshal("@./test/include/app.yaml", WithIncludes(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling with includes: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name": "app",
+		"tls": map[string]interface{}{
+			"cert": "/etc/cert.pem",
+			"ca": map[string]interface{}{
+				"file": "/etc/ca.pem",
+			},
+		},
+		"handle": "alice",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("error unmarshalling with includes: expected %v, got %v", expected, result)
+	}
+
+	// without the option, references are left untouched
+	result, err = Unmarshal("@./test/include/app.yaml")
+	if err != nil {
+		t.Fatalf("error unmarshalling without includes: %v", err)
+	}
+	if result.(map[string]interface{})["tls"] != "@shared/tls.yaml" {
+		t.Fatalf("unexpected include resolution without option: %v", result)
+	}
+
+	// inline values resolve includes against the current directory
+	result, err = Unmarshal(`{"tls": "@./test/include/shared/tls.yaml"}`, WithIncludes(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling with includes: %v", err)
+	}
+	if !reflect.DeepEqual(result.(map[string]interface{})["tls"], expected["tls"]) {
+		t.Fatalf("error unmarshalling with includes: expected %v, got %v", expected["tls"], result)
+	}
+
+	// escaped references are literal values
+	result, err = Unmarshal(`{"a": "@@literal", "b": "\\@handle"}`, WithIncludes(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling escaped includes: %v", err)
+	}
+	if expected := map[string]interface{}{"a": "@literal", "b": "@handle"}; !reflect.DeepEqual(result, expected) {
+		t.Fatalf("error unmarshalling escaped includes: expected %v, got %v", expected, result)
+	}
+}
+
+func TestUnmarshalWithIncludedArchiveMember(t *testing.T) {
+	result, err := Unmarshal("@./test/include/bundled.yaml", WithIncludes(true))
+	if err != nil {
+		t.Fatalf("error including an archive member: %v", err)
+	}
+	config, ok := result.(map[string]interface{})["config"].(map[string]interface{})
+	if !ok || config["name"] != "myapp" {
+		t.Fatalf("invalid archive member included: %v", result)
+	}
+}
+
+func TestUnmarshalWithIncludedSources(t *testing.T) {
+	RegisterSource("include-db", FormatYAML, []byte("host: localhost\nport: 5432\n"))
+	defer RegisterSource("include-db", FormatYAML, nil)
+	dir := filepath.Join(t.TempDir(), "sub")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("db: \"@mem:include-db\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Unmarshal("@"+filepath.Join(dir, "app.yaml"), WithIncludes(true))
+	if err != nil {
+		t.Fatalf("error including a source from a subdirectory: %v", err)
+	}
+	expected := map[string]interface{}{"db": map[string]interface{}{"host": "localhost", "port": 5432}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("error including a source: expected %v, got %v", expected, result)
+	}
+
+	RegisterSource("include-cycle", FormatJSON, []byte(`{"next": "@mem:include-cycle"}`))
+	defer RegisterSource("include-cycle", FormatJSON, nil)
+	if _, err := Unmarshal("@mem:include-cycle", WithIncludes(true)); err == nil {
+		t.Fatal("no error on cyclic includes of sources")
+	}
+}
+
+func TestUnmarshalWithIncludesErrors(t *testing.T) {
+	if _, err := Unmarshal("@./test/include/cycle-a.yaml", WithIncludes(true)); err == nil {
+		t.Fatal("no error on cyclic includes")
+	}
+	if _, err := Unmarshal("@./test/include/app.yaml", WithIncludes(true), WithMaxIncludeDepth(1)); err == nil {
+		t.Fatal("no error on includes exceeding the maximum depth")
+	}
+	if _, err := Unmarshal("@./test/include/app.yaml", WithIncludes(true), WithMaxIncludeDepth(2)); err != nil {
+		t.Fatalf("error on includes within the maximum depth: %v", err)
+	}
+	if _, err := Unmarshal(`{"tls": "@./test/include/missing.yaml"}`, WithIncludes(true)); err == nil {
+		t.Fatal("no error on missing include")
+	}
+}
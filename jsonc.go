@@ -0,0 +1,88 @@
+package rawdata
+
+// prepareJSON applies the lenient JSON extensions enabled in the options to
+// the content, so that it can be decoded by the standard library.
+func prepareJSON(content []byte, o *options) []byte {
+	if o.allowComments {
+		content = stripJSONComments(content)
+	}
+	if o.allowTrailingCommas {
+		content = stripTrailingCommas(content)
+	}
+	return content
+}
+
+// stripJSONComments returns a copy of the content where all line ("// ...")
+// and block ("/* ... */") comments outside of strings have been replaced by
+// whitespace; newlines are kept, so that the line numbers of any decoding
+// errors are still meaningful.
+func stripJSONComments(content []byte) []byte {
+	result := make([]byte, len(content))
+	copy(result, content)
+	for i := 0; i < len(result); i++ {
+		switch {
+		case result[i] == '"':
+			i = skipJSONString(result, i)
+		case result[i] == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		case result[i] == '/' && i+1 < len(result) && result[i+1] == '*':
+			result[i], result[i+1] = ' ', ' '
+			for i += 2; i < len(result); i++ {
+				if result[i] == '*' && i+1 < len(result) && result[i+1] == '/' {
+					result[i], result[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if result[i] != '\n' && result[i] != '\r' {
+					result[i] = ' '
+				}
+			}
+		}
+	}
+	return result
+}
+
+// stripTrailingCommas returns a copy of the content where all commas outside
+// of strings that are only followed by whitespace before the closing bracket
+// of an array or object have been replaced by whitespace.
+func stripTrailingCommas(content []byte) []byte {
+	result := make([]byte, len(content))
+	copy(result, content)
+	for i := 0; i < len(result); i++ {
+		switch result[i] {
+		case '"':
+			i = skipJSONString(result, i)
+		case ',':
+			j := i + 1
+			for ; j < len(result) && isJSONWhitespace(result[j]); j++ {
+			}
+			if j < len(result) && (result[j] == ']' || result[j] == '}') {
+				result[i] = ' '
+			}
+		}
+	}
+	return result
+}
+
+// isJSONWhitespace returns whether the byte is whitespace according to the
+// JSON specification.
+func isJSONWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// skipJSONString returns the index of the closing quote of the string that
+// starts at the given index, or the index of the last byte if the string is
+// not terminated.
+func skipJSONString(content []byte, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(content) - 1
+}
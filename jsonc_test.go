@@ -0,0 +1,93 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripJSONComments(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1} // comment`:        `{"a": 1}           `,
+		"{/* a\nb */\"a\": 1}":       "{    \n    \"a\": 1}",
+		`{"url": "http://x/*y*/"}`:   `{"url": "http://x/*y*/"}`,
+		`{"q": "a \" // b"} // c`:    `{"q": "a \" // b"}     `,
+		`[1, /* unterminated`:        `[1,                `,
+		`{"a": "unterminated // x}`:  `{"a": "unterminated // x}`,
+		"[1, // one\n2] /**/ // end": "[1,       \n2]            ",
+	}
+	for input, expected := range tests {
+		if actual := string(stripJSONComments([]byte(input))); actual != expected {
+			t.Errorf("invalid stripping of %q: expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+func TestUnmarshalJSONWithComments(t *testing.T) {
+	expected := map[string]interface{}{
+		"name":     "John",
+		"homepage": "http://example.com/*not-a-comment*/",
+		"age":      float64(23),
+	}
+	if _, err := Unmarshal("@./test/commented.jsonc"); err == nil {
+		t.Fatal("no error unmarshalling JSON with comments by default")
+	}
+	result, err := Unmarshal("@./test/commented.jsonc", WithAllowComments(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling JSON with comments: %v", err)
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+	target := &s{}
+	if err := UnmarshalInto("// inline\n{\"name\": \"John\" /* ok */}", target, WithAllowComments(true)); err != nil {
+		t.Fatalf("error unmarshalling inline JSON with comments: %v", err)
+	}
+	if target.Name != "John" {
+		t.Errorf("invalid target: %+v", target)
+	}
+	result, err = Unmarshal(`{"name": "John"} // comment`, WithAllowComments(true), WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling ordered JSON with comments: %v", err)
+	}
+	if object, ok := result.(*OrderedMap); !ok || object.Len() != 1 {
+		t.Errorf("invalid ordered result: %v", result)
+	}
+}
+
+func TestStripTrailingCommas(t *testing.T) {
+	tests := map[string]string{
+		`[1,2,3,]`:           `[1,2,3 ]`,
+		"{\"a\": [1,\n],\n}": "{\"a\": [1 \n] \n}",
+		`["a,]", "b,}",]`:    `["a,]", "b,}" ]`,
+		`{"q": "\",]"}`:      `{"q": "\",]"}`,
+		`[1, 2]`:             `[1, 2]`,
+		`[1,,]`:              `[1, ]`,
+	}
+	for input, expected := range tests {
+		if actual := string(stripTrailingCommas([]byte(input))); actual != expected {
+			t.Errorf("invalid stripping of %q: expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+func TestUnmarshalJSONWithTrailingCommas(t *testing.T) {
+	if _, err := Unmarshal("[1,2,3,]"); err == nil {
+		t.Fatal("no error unmarshalling JSON with trailing commas by default")
+	}
+	result, err := Unmarshal("[1,2,3,]", WithAllowTrailingCommas(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling JSON with trailing commas: %v", err)
+	}
+	if !reflect.DeepEqual(result, []interface{}{float64(1), float64(2), float64(3)}) {
+		t.Errorf("invalid result: %v", result)
+	}
+	input := "{\n  \"name\": \"Doe, John\", // full name\n  \"tags\": [\"a,]\",],\n}"
+	result, err = Unmarshal(input, WithAllowTrailingCommas(true), WithAllowComments(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling JSON with comments and trailing commas: %v", err)
+	}
+	expected := map[string]interface{}{"name": "Doe, John", "tags": []interface{}{"a,]"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+}
@@ -0,0 +1,44 @@
+package rawdata
+
+// Limits groups the limits on the data that is read and decoded, so that they
+// can be set together via WithLimits, e.g. to define a strict profile for
+// untrusted input and a lenient one for trusted configuration. In all fields,
+// 0 means that there is no limit, so the zero value sets no limits at all; see
+// DefaultLimits for the limits that apply when none are set.
+type Limits struct {
+	// MaxFileSize is the maximum size in bytes of the data that can be read
+	// from a file, standard input or any other source (see WithMaxFileSize);
+	// the default is DefaultMaxFileSize.
+	MaxFileSize int64
+	// MaxDepth is the maximum nesting depth of the data (see WithMaxDepth);
+	// by default there is no limit.
+	MaxDepth int
+	// MaxAliasExpansion is the maximum number of nodes that the aliases in
+	// YAML data can expand to (see WithMaxAliasExpansion); the default is
+	// DefaultMaxAliasExpansion.
+	MaxAliasExpansion int
+	// MaxIncludeDepth is the maximum nesting level of includes (see
+	// WithMaxIncludeDepth); by default there is no limit.
+	MaxIncludeDepth int
+}
+
+// DefaultLimits returns the limits that apply when none are set, as a
+// starting point for custom ones.
+func DefaultLimits() Limits {
+	return newOptions().limits()
+}
+
+// limits returns the limits set in the options.
+func (o *options) limits() Limits {
+	return Limits{
+		MaxFileSize:       o.maxFileSize,
+		MaxDepth:          o.maxDepth,
+		MaxAliasExpansion: o.maxAliasExpansion,
+		MaxIncludeDepth:   o.maxIncludeDepth,
+	}
+}
+
+// Limits returns the limits the decoder applies.
+func (d *Decoder) Limits() Limits {
+	return d.o.limits()
+}
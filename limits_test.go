@@ -0,0 +1,48 @@
+package rawdata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultLimits(t *testing.T) {
+	expected := Limits{MaxFileSize: DefaultMaxFileSize, MaxAliasExpansion: DefaultMaxAliasExpansion}
+	if limits := DefaultLimits(); limits != expected {
+		t.Errorf("invalid default limits: %+v", limits)
+	}
+	if limits := NewDecoder().Limits(); limits != expected {
+		t.Errorf("invalid decoder limits: %+v", limits)
+	}
+}
+
+func TestWithLimits(t *testing.T) {
+	strict := Limits{MaxFileSize: 64, MaxDepth: 2, MaxAliasExpansion: 3, MaxIncludeDepth: 1}
+	decoder := NewDecoder(WithLimits(strict))
+	if limits := decoder.Limits(); limits != strict {
+		t.Errorf("invalid decoder limits: %+v", limits)
+	}
+	for input, expected := range map[string]error{
+		"[" + strings.Repeat(" ", 64) + "]": ErrFileTooLarge,
+		`{"a": {"b": [1]}}`:                 ErrMaxDepthExceeded,
+		"---\na: &a [1, 2, 3]\nb: *a\n":     ErrAliasBudgetExceeded,
+	} {
+		var err error
+		if expected == ErrFileTooLarge {
+			_, err = decoder.Unmarshal("data:application/json," + input)
+		} else {
+			_, err = decoder.Unmarshal(input)
+		}
+		if !errors.Is(err, expected) {
+			t.Errorf("invalid error for %q: expected %v, got %v", input, expected, err)
+		}
+	}
+	// no limits at all
+	if limits := NewDecoder(WithLimits(Limits{})).Limits(); limits != (Limits{}) {
+		t.Errorf("invalid limits: %+v", limits)
+	}
+	// single limits can be overridden afterwards
+	if limits := NewDecoder(WithLimits(strict), WithMaxDepth(10)).Limits(); limits.MaxDepth != 10 || limits.MaxFileSize != 64 {
+		t.Errorf("invalid limits: %+v", limits)
+	}
+}
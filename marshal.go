@@ -0,0 +1,194 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal serialises the given value into the given format; JSON, YAML and
+// TOML are indented with two spaces, for readability, unless configured
+// otherwise (see WithIndent and WithCompact). A Document (see
+// WithPreserveComments) is written into YAML with its comments.
+func Marshal(v interface{}, format Format, opts ...Option) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := encode(&buffer, v, format, newOptions(opts...)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// encode serialises the given value into the given format, writing it to w
+// as it goes (see Marshal).
+func encode(w io.Writer, v interface{}, format Format, o *options) error {
+	if document, ok := v.(*Document); ok {
+		if format == FormatYAML {
+			// the document node carries the comments around the root, too
+			return encodeYAML(w, &document.node, o)
+		}
+		value, err := document.Value()
+		if err != nil {
+			return err
+		}
+		v = value
+	}
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		if !o.compact {
+			encoder.SetIndent("", o.indent)
+		}
+		return encoder.Encode(v)
+	case FormatYAML:
+		return encodeYAML(w, yamlValue(v), o)
+	case FormatTOML:
+		encoder := toml.NewEncoder(w)
+		encoder.Indent = o.indent
+		if o.compact {
+			encoder.Indent = ""
+		}
+		// the TOML encoder knows nothing about ordered maps
+		return encoder.Encode(plainValue(v))
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, format)
+	}
+}
+
+// encodeYAML serialises the given value into YAML; the indentation is that
+// set in the options, if it is made of 2 to 9 spaces, the only ones YAML
+// supports, or else two spaces.
+func encodeYAML(w io.Writer, v interface{}, o *options) error {
+	encoder := yaml.NewEncoder(w)
+	indent := 2
+	if n := len(o.indent); n >= 2 && n <= 9 && strings.Trim(o.indent, " ") == "" {
+		indent = n
+	}
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// MarshalToFile serialises the given value into the given file, in the format
+// matching its extension (.json, .yaml, .yml or .toml, unless mapped otherwise
+// via WithExtensionMap); the file is written to the local filesystem, or to
+// the one set via WithAferoFs if the package is built with the "afero" tag.
+func MarshalToFile(v interface{}, filename string, opts ...Option) error {
+	o := newOptions(opts...)
+	ext := filepath.Ext(filename)
+	format := formatFromExtension(ext, o)
+	if format == FormatUnknown {
+		return fmt.Errorf("%w in file: %s", ErrUnsupportedFormat, ext)
+	}
+	data, err := Marshal(v, format, opts...)
+	if err != nil {
+		return err
+	}
+	if o.writeFile != nil {
+		return o.writeFile(resolvePath(filename, o), data, 0644)
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Convert unmarshals the given value (see Unmarshal) and marshals it again
+// into the given format; key order is preserved if WithOrderedMaps is
+// enabled (except for TOML, whose encoder sorts keys), and numbers are kept
+// intact if WithUseNumber is.
+func Convert(value string, to Format, opts ...Option) ([]byte, error) {
+	if to == FormatUnknown {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, to)
+	}
+	result, err := Unmarshal(value, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Marshal(result, to, opts...)
+}
+
+// ConvertStream is like Convert, but it writes the output to w as it is
+// encoded, rather than returning it, so that large documents can be written
+// to a file or to an HTTP response without holding their encoded form in
+// memory; the input is still decoded in full. If encoding fails, part of the
+// output may have been written already.
+func ConvertStream(value string, to Format, w io.Writer, opts ...Option) error {
+	if to == FormatUnknown {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, to)
+	}
+	o := newOptions(opts...)
+	result, _, err := unmarshalWithFormat(value, o)
+	if err != nil {
+		return err
+	}
+	return encode(w, result, to, o)
+}
+
+// plainValue returns a copy of the given value where all ordered maps have
+// been replaced by plain maps.
+func plainValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *OrderedMap:
+		object := make(map[string]interface{}, v.Len())
+		v.Range(func(key string, value interface{}) bool {
+			object[key] = plainValue(value)
+			return true
+		})
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[key] = plainValue(value)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, value := range v {
+			array[i] = plainValue(value)
+		}
+		return array
+	default:
+		return v
+	}
+}
+
+// yamlValue returns a copy of the given value where all json.Numbers have
+// been replaced by YAML nodes, so that they are written as numbers and not as
+// strings.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case *OrderedMap:
+		object := NewOrderedMap()
+		v.Range(func(key string, value interface{}) bool {
+			object.Set(key, yamlValue(value))
+			return true
+		})
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[key] = yamlValue(value)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i, value := range v {
+			array[i] = yamlValue(value)
+		}
+		return array
+	default:
+		return v
+	}
+}
@@ -0,0 +1,175 @@
+package rawdata
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	value := map[string]interface{}{"name": "John", "age": 23}
+	expected := map[Format]string{
+		FormatJSON: "{\n  \"age\": 23,\n  \"name\": \"John\"\n}\n",
+		FormatYAML: "age: 23\nname: John\n",
+		FormatTOML: "age = 23\nname = \"John\"\n",
+	}
+	for format, text := range expected {
+		data, err := Marshal(value, format)
+		if err != nil {
+			t.Fatalf("error marshalling to %v: %v", format, err)
+		}
+		if string(data) != text {
+			t.Errorf("invalid %v data: expected %q, got %q", format, text, data)
+		}
+	}
+	if _, err := Marshal(value, FormatUnknown); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("invalid error marshalling to unknown format: %v", err)
+	}
+}
+
+func TestMarshalOrderedMapToTOML(t *testing.T) {
+	result, err := Unmarshal(`{"name": "John", "address": {"city": "London"}}`, WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	data, err := Marshal(result, FormatTOML)
+	if err != nil {
+		t.Fatalf("error marshalling to TOML: %v", err)
+	}
+	if !strings.Contains(string(data), "[address]\n  city = \"London\"") {
+		t.Errorf("invalid TOML data: %q", data)
+	}
+}
+
+func TestMarshalToFile(t *testing.T) {
+	value := map[string]interface{}{
+		"name":    "John",
+		"address": map[string]interface{}{"city": "London"},
+	}
+	directory := t.TempDir()
+	for _, name := range []string{"out.json", "out.yaml", "out.yml", "out.toml"} {
+		filename := filepath.Join(directory, name)
+		if err := MarshalToFile(value, filename); err != nil {
+			t.Fatalf("error marshalling to %s: %v", name, err)
+		}
+		result, err := Unmarshal("@" + filename)
+		if err != nil {
+			t.Fatalf("error unmarshalling %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(result, value) {
+			t.Errorf("invalid round-trip through %s: %v", name, result)
+		}
+	}
+	if err := MarshalToFile(value, filepath.Join(directory, "out.txt")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("invalid error marshalling to unsupported extension: %v", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	data, err := Convert("@./test/struct.yaml", FormatJSON)
+	if err != nil {
+		t.Fatalf("error converting YAML to JSON: %v", err)
+	}
+	expected := "{\n  \"age\": 23,\n  \"name\": \"John\",\n  \"surname\": \"Doe\"\n}\n"
+	if string(data) != expected {
+		t.Errorf("invalid JSON: expected %q, got %q", expected, data)
+	}
+	if _, err := Convert("@./test/struct.yaml", FormatUnknown); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("invalid error converting to unknown format: %v", err)
+	}
+}
+
+func TestConvertOrderedNumbers(t *testing.T) {
+	input := `{"zeta": 12345678901234567890, "alpha": 1.50, "list": [1, 2]}`
+	data, err := Convert(input, FormatYAML, WithOrderedMaps(true), WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error converting JSON to YAML: %v", err)
+	}
+	expected := "zeta: 12345678901234567890\nalpha: 1.50\nlist:\n  - 1\n  - 2\n"
+	if string(data) != expected {
+		t.Errorf("invalid YAML: expected %q, got %q", expected, data)
+	}
+	data, err = Convert(input, FormatTOML, WithOrderedMaps(true), WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error converting JSON to TOML: %v", err)
+	}
+	if !strings.Contains(string(data), "alpha = 1.5\nlist = [1, 2]\n") {
+		t.Errorf("invalid TOML: %q", data)
+	}
+}
+
+// failingWriter is an io.Writer that always fails.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestConvertStream(t *testing.T) {
+	for _, to := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		expected, err := Convert("@./test/struct.yaml", to, WithOrderedMaps(true))
+		if err != nil {
+			t.Fatalf("error converting to %v: %v", to, err)
+		}
+		var buffer bytes.Buffer
+		if err := ConvertStream("@./test/struct.yaml", to, &buffer, WithOrderedMaps(true)); err != nil {
+			t.Fatalf("error streaming to %v: %v", to, err)
+		}
+		if buffer.String() != string(expected) {
+			t.Errorf("invalid output streamed to %v: expected %q, got %q", to, expected, buffer.String())
+		}
+	}
+	if err := ConvertStream("@./test/struct.yaml", FormatJSON, failingWriter{}); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected write error, got %v", err)
+	}
+	if err := ConvertStream("@./test/struct.yaml", FormatUnknown, &bytes.Buffer{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if err := ConvertStream("@./test/missing.yaml", FormatJSON, &bytes.Buffer{}); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+}
+
+func TestMarshalIndentation(t *testing.T) {
+	value := NewOrderedMap()
+	value.Set("name", "John")
+	value.Set("tags", []interface{}{"a", "b"})
+	address := NewOrderedMap()
+	address.Set("city", "London")
+	value.Set("address", address)
+	testCases := []struct {
+		format   Format
+		opts     []Option
+		expected string
+	}{
+		{FormatJSON, nil, "{\n  \"name\": \"John\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"address\": {\n    \"city\": \"London\"\n  }\n}\n"},
+		{FormatJSON, []Option{WithIndent("\t")}, "{\n\t\"name\": \"John\",\n\t\"tags\": [\n\t\t\"a\",\n\t\t\"b\"\n\t],\n\t\"address\": {\n\t\t\"city\": \"London\"\n\t}\n}\n"},
+		{FormatJSON, []Option{WithCompact(true)}, "{\"name\":\"John\",\"tags\":[\"a\",\"b\"],\"address\":{\"city\":\"London\"}}\n"},
+		{FormatYAML, nil, "name: John\ntags:\n  - a\n  - b\naddress:\n  city: London\n"},
+		{FormatYAML, []Option{WithIndent("    ")}, "name: John\ntags:\n    - a\n    - b\naddress:\n    city: London\n"},
+		{FormatYAML, []Option{WithIndent("\t")}, "name: John\ntags:\n  - a\n  - b\naddress:\n  city: London\n"},
+		{FormatYAML, []Option{WithCompact(true)}, "name: John\ntags:\n  - a\n  - b\naddress:\n  city: London\n"},
+		{FormatTOML, []Option{WithIndent("    ")}, "name = \"John\"\ntags = [\"a\", \"b\"]\n\n[address]\n    city = \"London\"\n"},
+		{FormatTOML, []Option{WithCompact(true)}, "name = \"John\"\ntags = [\"a\", \"b\"]\n\n[address]\ncity = \"London\"\n"},
+	}
+	for _, test := range testCases {
+		data, err := Marshal(value, test.format, test.opts...)
+		if err != nil {
+			t.Fatalf("error marshalling to %v: %v", test.format, err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("invalid %v output: expected %q, got %q", test.format, test.expected, data)
+		}
+	}
+	data, err := Convert(`{"a": [1]}`, FormatJSON, WithCompact(true))
+	if err != nil || string(data) != "{\"a\":[1]}\n" {
+		t.Errorf("invalid compact conversion: %q (error: %v)", data, err)
+	}
+	var buffer bytes.Buffer
+	if err := ConvertStream(`{"a": [1]}`, FormatJSON, &buffer, WithIndent(" ")); err != nil || buffer.String() != "{\n \"a\": [\n  1\n ]\n}\n" {
+		t.Errorf("invalid indented conversion: %q (error: %v)", buffer.String(), err)
+	}
+}
@@ -0,0 +1,216 @@
+package rawdata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UnmarshalMerged unmarshals each of the given values (see Unmarshal) and
+// deep-merges the results left to right, so that values coming later in the
+// list override the ones coming earlier: nested maps are merged key by key,
+// whereas scalars and arrays are replaced wholesale. Merging a map with
+// anything other than another map (e.g. an array) is an error. The values are
+// loaded one at a time with the default options; see UnmarshalMergedWith to
+// set options, e.g. to load them concurrently (see WithConcurrency).
+func UnmarshalMerged(values ...string) (interface{}, error) {
+	return unmarshalMerged(values, newOptions())
+}
+
+// UnmarshalMergedWith is like UnmarshalMerged, but it accepts options, which
+// apply to all the values; with WithConcurrency, the values are loaded
+// concurrently, but they are still merged in order.
+func UnmarshalMergedWith(values []string, opts ...Option) (interface{}, error) {
+	return unmarshalMerged(values, newOptions(opts...))
+}
+
+// unmarshalMerged is the implementation of UnmarshalMerged: the values are
+// loaded concurrently if the options allow it (see WithConcurrency), but they
+// are always merged in order.
+func unmarshalMerged(values []string, o *options) (interface{}, error) {
+	results, err := unmarshalEach(values, o)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	for i, v := range results {
+		if i == 0 {
+			result = v
+			continue
+		}
+		if result, err = Merge(result, v); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// unmarshalEach unmarshals each of the given values, with at most as many
+// running at the same time as allowed by the options; the first error cancels
+// the loading of the values still in progress, and is returned.
+func unmarshalEach(values []string, o *options) ([]interface{}, error) {
+	results := make([]interface{}, len(values))
+	if o.concurrency <= 1 || len(values) <= 1 {
+		for i, value := range values {
+			result, _, err := unmarshalWithFormat(value, o)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+	ctx, cancel := context.WithCancel(o.context())
+	defer cancel()
+	var (
+		wg        sync.WaitGroup
+		lock      sync.Mutex
+		first     error
+		semaphore = make(chan struct{}, o.concurrency)
+	)
+	for i, value := range values {
+		wg.Add(1)
+		go func(i int, value string) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				lock.Lock()
+				if first == nil {
+					// the parent context was cancelled
+					first = ctx.Err()
+				}
+				lock.Unlock()
+				return
+			}
+			c := *o
+			c.ctx = ctx
+			result, _, err := unmarshalWithFormat(value, &c)
+			if err != nil {
+				lock.Lock()
+				if first == nil {
+					// the errors that follow are most likely due to the
+					// cancellation
+					first = err
+					cancel()
+				}
+				lock.Unlock()
+				return
+			}
+			results[i] = result
+		}(i, value)
+	}
+	wg.Wait()
+	if first != nil {
+		return nil, first
+	}
+	return results, nil
+}
+
+// Merge recursively merges two values as returned by Unmarshal and returns
+// the result, leaving the inputs untouched: maps are merged key by key, with
+// nested maps being merged recursively, whereas for scalars and arrays the
+// value in src overrides the one in dst. Merging a map with anything other
+// than another map (e.g. an array) at the same path is an error. If either
+// map is ordered (see WithOrderedMaps), so is the result: the keys of dst
+// keep their order, followed by the new keys in src.
+func Merge(dst, src interface{}) (interface{}, error) {
+	return merge(dst, src, nil)
+}
+
+// merge recursively merges src into dst and returns the result; the inputs
+// are not modified. The path is the sequence of keys leading to the values
+// being merged, and is used to provide context in error messages.
+func merge(dst, src interface{}, path []string) (interface{}, error) {
+	dstMap, dstIsMap := dst.(map[string]interface{})
+	srcMap, srcIsMap := src.(map[string]interface{})
+	dstOrdered, dstIsOrdered := dst.(*OrderedMap)
+	srcOrdered, srcIsOrdered := src.(*OrderedMap)
+	switch {
+	case (dstIsOrdered || srcIsOrdered) && (dstIsMap || dstIsOrdered) && (srcIsMap || srcIsOrdered):
+		// the keys of dst keep their order, the new ones in src follow
+		result := NewOrderedMap()
+		for _, k := range orderedKeys(dstMap, dstOrdered) {
+			v, _ := mapValue(dstMap, dstOrdered, k)
+			result.Set(k, v)
+		}
+		for _, k := range orderedKeys(srcMap, srcOrdered) {
+			v, _ := mapValue(srcMap, srcOrdered, k)
+			if existing, ok := result.Get(k); ok {
+				merged, err := merge(existing, v, append(path, k))
+				if err != nil {
+					return nil, err
+				}
+				v = merged
+			}
+			result.Set(k, v)
+		}
+		return result, nil
+	case dstIsMap && srcIsMap:
+		result := make(map[string]interface{}, len(dstMap)+len(srcMap))
+		for k, v := range dstMap {
+			result[k] = v
+		}
+		for k, v := range srcMap {
+			if existing, ok := result[k]; ok {
+				merged, err := merge(existing, v, append(path, k))
+				if err != nil {
+					return nil, err
+				}
+				result[k] = merged
+			} else {
+				result[k] = v
+			}
+		}
+		return result, nil
+	case dstIsMap || srcIsMap || dstIsOrdered || srcIsOrdered:
+		if len(path) == 0 {
+			return nil, fmt.Errorf("cannot merge %s into %s", kindOf(src), kindOf(dst))
+		}
+		return nil, fmt.Errorf("cannot merge %s into %s at '%s'", kindOf(src), kindOf(dst), strings.Join(path, "."))
+	default:
+		return src, nil
+	}
+}
+
+// orderedKeys returns the keys of a map, which is either the plain map or the
+// ordered one, in order; the keys of plain maps are sorted.
+func orderedKeys(plain map[string]interface{}, ordered *OrderedMap) []string {
+	if ordered != nil {
+		return ordered.Keys()
+	}
+	keys := make([]string, 0, len(plain))
+	for k := range plain {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mapValue returns the value of the key in a map, which is either the plain
+// map or the ordered one.
+func mapValue(plain map[string]interface{}, ordered *OrderedMap, key string) (interface{}, bool) {
+	if ordered != nil {
+		return ordered.Get(key)
+	}
+	v, ok := plain[key]
+	return v, ok
+}
+
+// kindOf returns a human readable description of the kind of a value
+// returned by Unmarshal, for use in error messages.
+func kindOf(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}, *OrderedMap:
+		return "object"
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	default:
+		return "scalar"
+	}
+}
@@ -0,0 +1,217 @@
+package rawdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalMerged(t *testing.T) {
+	result, err := UnmarshalMerged("@./test/base.json", "@./test/override.yaml", `{"age": 23}`)
+	if err != nil {
+		t.Fatalf("error unmarshalling merged values: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name":    "John",
+		"surname": "Smith",
+		"age":     float64(23),
+		"address": map[string]interface{}{
+			"street": "Baker Street",
+			"city":   "London",
+		},
+		"tags": []interface{}{"three"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("invalid merged value: expected %v, got %v", expected, result)
+	}
+}
+
+func TestUnmarshalMergedWith(t *testing.T) {
+	values := []string{"@./test/base.json", "@./test/override.yaml", `{"age": 23}`}
+	result, err := UnmarshalMergedWith(values, WithConcurrency(2), WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling merged values with options: %v", err)
+	}
+	if age, _ := GetPath(result, "age"); age != json.Number("23") {
+		t.Errorf("options not applied: %T %v", age, age)
+	}
+	if surname, _ := GetPath(result, "surname"); surname != "Smith" {
+		t.Errorf("values not merged in order: %v", result)
+	}
+	if _, err := UnmarshalMergedWith(values, WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("invalid error with file access disabled: %v", err)
+	}
+}
+
+func TestUnmarshalMergedIncompatible(t *testing.T) {
+	if _, err := UnmarshalMerged("@./test/struct.json", "@./test/array.json"); err == nil {
+		t.Fatal("no error merging an object and an array")
+	}
+	if _, err := UnmarshalMerged("@./test/base.json", `{"address": ["a", "b"]}`); err == nil {
+		t.Fatal("no error merging an object and an array")
+	}
+	if _, err := UnmarshalMerged("@./test/base.json", "@./test/nonexisting.json"); err == nil {
+		t.Fatal("no error on non-existing file")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{
+			"c": "x",
+			"d": []interface{}{1, 2},
+		},
+	}
+	src := map[string]interface{}{
+		"b": map[string]interface{}{
+			"d": []interface{}{3},
+			"e": true,
+		},
+		"f": nil,
+	}
+	result, err := Merge(dst, src)
+	if err != nil {
+		t.Fatalf("error merging: %v", err)
+	}
+	expected := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{
+			"c": "x",
+			"d": []interface{}{3},
+			"e": true,
+		},
+		"f": nil,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("invalid merged value: expected %v, got %v", expected, result)
+	}
+	// inputs must be left untouched
+	if _, ok := dst["b"].(map[string]interface{})["e"]; ok {
+		t.Fatal("merge modified its input")
+	}
+
+	if result, err := Merge([]interface{}{1, 2}, []interface{}{3}); err != nil || !reflect.DeepEqual(result, []interface{}{3}) {
+		t.Fatalf("invalid merged arrays: %v (error: %v)", result, err)
+	}
+	if result, err := Merge("a", 2); err != nil || result != 2 {
+		t.Fatalf("invalid merged scalars: %v (error: %v)", result, err)
+	}
+	for _, pair := range [][2]interface{}{
+		{map[string]interface{}{}, []interface{}{}},
+		{[]interface{}{}, map[string]interface{}{}},
+		{map[string]interface{}{"a": map[string]interface{}{}}, map[string]interface{}{"a": "x"}},
+	} {
+		if _, err := Merge(pair[0], pair[1]); err == nil {
+			t.Fatalf("no error merging %v into %v", pair[1], pair[0])
+		}
+	}
+}
+
+func TestUnmarshalMergedConcurrently(t *testing.T) {
+	var running, peak int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		switch r.URL.Path {
+		case "/fail.json":
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		case "/hang.json":
+			// only returns when the request is cancelled
+			<-r.Context().Done()
+			return
+		}
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"source": %q, %q: true}`, r.URL.Path, r.URL.Path)
+	}))
+	defer server.Close()
+
+	values := []string{server.URL + "/a.json", server.URL + "/b.json", server.URL + "/c.json", server.URL + "/d.json"}
+	go func() {
+		// let the requests pile up before releasing them
+		for atomic.LoadInt32(&running) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+	}()
+	result, err := NewDecoder(WithConcurrency(2)).UnmarshalMerged(values...)
+	if err != nil {
+		t.Fatalf("error unmarshalling concurrently: %v", err)
+	}
+	if source, _ := GetPath(result, "source"); source != "/d.json" {
+		t.Errorf("values not merged in order: %v", result)
+	}
+	for _, path := range []string{"/a.json", "/b.json", "/c.json", "/d.json"} {
+		if _, ok := GetPath(result, path, WithSeparator("|")); !ok {
+			t.Errorf("missing value from %s: %v", path, result)
+		}
+	}
+	if peak := atomic.LoadInt32(&peak); peak != 2 {
+		t.Errorf("invalid number of concurrent loads: %d", peak)
+	}
+
+	start := time.Now()
+	_, err = UnmarshalMergedContext(context.Background(), []string{server.URL + "/hang.json", server.URL + "/fail.json"}, WithConcurrency(4))
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected error from failing source, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("loading not cancelled after error: %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := UnmarshalMergedContext(ctx, values, WithConcurrency(2)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancellation error, got %v", err)
+	}
+}
+
+func TestUnmarshalMergedOrdered(t *testing.T) {
+	values := []string{
+		`{"name": "app", "server": {"port": 80, "host": "localhost"}, "debug": false}`,
+		"---\nzone: eu\nserver:\n  tls: true\n  port: 443\ndebug: true\n",
+	}
+	result, err := NewDecoder(WithOrderedMaps(true)).UnmarshalMerged(values...)
+	if err != nil {
+		t.Fatalf("error merging ordered values: %v", err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("error encoding merged values: %v", err)
+	}
+	if expected := `{"name":"app","server":{"port":443,"host":"localhost","tls":true},"debug":true,"zone":"eu"}`; string(data) != expected {
+		t.Errorf("invalid merged values: expected %s, got %s", expected, data)
+	}
+	result, err = UnmarshalMergedContext(context.Background(), values, WithOrderedMaps(true), WithConcurrency(2))
+	if data, _ := json.Marshal(result); err != nil || string(data) != `{"name":"app","server":{"port":443,"host":"localhost","tls":true},"debug":true,"zone":"eu"}` {
+		t.Errorf("invalid concurrently merged values: %s (%v)", data, err)
+	}
+
+	// plain maps merged into ordered ones have their keys sorted
+	ordered := NewOrderedMap()
+	ordered.Set("b", 1)
+	merged, err := Merge(ordered, map[string]interface{}{"d": 2, "c": 3, "b": 4})
+	if data, _ := json.Marshal(merged); err != nil || string(data) != `{"b":4,"c":3,"d":2}` {
+		t.Errorf("invalid mixed merge: %s (%v)", data, err)
+	}
+	if _, err := Merge(ordered, []interface{}{1}); err == nil {
+		t.Errorf("expected error merging array into ordered map")
+	}
+}
@@ -0,0 +1,53 @@
+package rawdata
+
+import (
+	"bytes"
+	"errors"
+)
+
+// unmarshalNDJSON unmarshals newline-delimited JSON into an array with an
+// element for each line; empty lines are skipped. Each line is decoded on its
+// own, so that errors can be reported with their line number; lines that
+// cannot be decoded are passed to the handler set via WithInvalidLineHandler,
+// if any.
+func unmarshalNDJSON(content []byte, o *options) (interface{}, error) {
+	result := []interface{}{}
+	err := eachNDJSONLine(content, o, func(line []byte) error {
+		value, err := decode(FormatJSON, line, o)
+		if err == nil {
+			result = append(result, value)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// eachNDJSONLine calls fn for each non-empty line of newline-delimited JSON;
+// decoding errors returned by fn are reported with the line number, and
+// passed to the handler set via WithInvalidLineHandler, if any.
+func eachNDJSONLine(content []byte, o *options, fn func(line []byte) error) error {
+	for number, line := range bytes.Split(content, []byte("\n")) {
+		if isEmpty(line) {
+			continue
+		}
+		err := fn(line)
+		if err == nil {
+			continue
+		}
+		var decodeError *DecodeError
+		if !errors.As(err, &decodeError) {
+			return err
+		}
+		lineError := &DecodeError{DataFormat: FormatNDJSON, Line: number + 1, Column: decodeError.Column, Err: decodeError.Err}
+		if o.invalidLine == nil {
+			return lineError
+		}
+		if err := o.invalidLine(lineError); err != nil {
+			return err
+		}
+	}
+	return nil
+}
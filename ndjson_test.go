@@ -0,0 +1,76 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalNDJSON(t *testing.T) {
+	result, format, err := UnmarshalWithFormat("@./test/events.ndjson")
+	if err != nil {
+		t.Fatalf("error unmarshalling NDJSON file: %v", err)
+	}
+	if format != FormatNDJSON {
+		t.Errorf("invalid format: expected ndjson, got %v", format)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"event": "login", "user": "alice"},
+		map[string]interface{}{"event": "view", "page": 3.0},
+		map[string]interface{}{"event": "logout", "user": "alice"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+	type event struct {
+		Event string `json:"event"`
+		User  string `json:"user"`
+	}
+	events := []event{}
+	if err := UnmarshalInto("jsonl:[1]\n{\"event\": \"login\"}", &events); err == nil {
+		t.Error("no error unmarshalling mismatched NDJSON into typed target")
+	}
+	if err := UnmarshalInto("@./test/events.ndjson", &events); err != nil {
+		t.Fatalf("error unmarshalling NDJSON file into typed target: %v", err)
+	}
+	if len(events) != 3 || events[2].Event != "logout" || events[2].User != "alice" {
+		t.Errorf("invalid typed result: %v", events)
+	}
+	documents, err := UnmarshalAll("@./test/events.ndjson")
+	if err != nil {
+		t.Fatalf("error unmarshalling all NDJSON documents: %v", err)
+	}
+	if len(documents) != 3 {
+		t.Errorf("invalid number of documents: expected 3, got %d", len(documents))
+	}
+}
+
+func TestUnmarshalInvalidNDJSON(t *testing.T) {
+	input := "ndjson:{\"a\": 1}\n{\"a\": }\n\n{\"a\": 3}\n[4"
+	_, err := Unmarshal(input)
+	var decodeError *DecodeError
+	if !errors.As(err, &decodeError) {
+		t.Fatalf("invalid error for invalid NDJSON: %v", err)
+	}
+	if decodeError.Format() != FormatNDJSON || decodeError.Line != 2 {
+		t.Errorf("invalid error position: %v", decodeError)
+	}
+	lines := []int{}
+	result, err := Unmarshal(input, WithInvalidLineHandler(func(err *DecodeError) error {
+		lines = append(lines, err.Line)
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("error unmarshalling NDJSON with invalid lines skipped: %v", err)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 5}) {
+		t.Errorf("invalid lines reported: %v", lines)
+	}
+	if len(result.([]interface{})) != 2 {
+		t.Errorf("invalid result: %v", result)
+	}
+	stop := errors.New("stop")
+	if _, err := Unmarshal(input, WithInvalidLineHandler(func(err *DecodeError) error { return stop })); !errors.Is(err, stop) {
+		t.Errorf("invalid error from handler: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberMode is the representation of numbers in the values returned by
+// Unmarshal and the like (see WithNumberMode).
+type NumberMode uint8
+
+const (
+	// NumberModeDefault leaves numbers as the library decoding each format
+	// returns them: float64 for JSON, int or float64 for YAML, int64 or
+	// float64 for TOML (unless WithUseNumber is set).
+	NumberModeDefault NumberMode = iota
+	// NumberModeAllFloat64 turns all numbers, integers included, into
+	// float64, as in JSON; integers beyond 2^53 may lose precision.
+	NumberModeAllFloat64
+	// NumberModeAllJSONNumber turns all numbers into json.Numbers, which keep
+	// their literal representation in JSON and YAML data; integers from other
+	// formats are written in decimal and floats in the shortest form that
+	// represents them exactly (e.g. 0.5 or 1e+21). Infinities and NaNs, which
+	// have no JSON representation, are left as float64.
+	NumberModeAllJSONNumber
+	// NumberModePreferInt turns numbers that are written as integers (e.g.
+	// 42, but not 42.0 or 4.2e1) into int, as long as they fit, and all other
+	// numbers into float64; integers from formats that have no literals, e.g.
+	// TOML, become int too.
+	NumberModePreferInt
+)
+
+// String returns the name of the number mode, e.g. "float64".
+func (m NumberMode) String() string {
+	switch m {
+	case NumberModeDefault:
+		return "default"
+	case NumberModeAllFloat64:
+		return "float64"
+	case NumberModeAllJSONNumber:
+		return "json.Number"
+	case NumberModePreferInt:
+		return "int"
+	default:
+		return fmt.Sprintf("NumberMode(%d)", uint8(m))
+	}
+}
+
+// normaliseNumbers returns the value with all the numbers in it converted
+// according to the given mode; maps and arrays are modified in place.
+func normaliseNumbers(v interface{}, mode NumberMode) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = normaliseNumbers(value, mode)
+		}
+	case *OrderedMap:
+		for _, key := range v.Keys() {
+			value, _ := v.Get(key)
+			v.Set(key, normaliseNumbers(value, mode))
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normaliseNumbers(value, mode)
+		}
+	case []map[string]interface{}:
+		// arrays of tables, in TOML
+		for _, value := range v {
+			normaliseNumbers(value, mode)
+		}
+	case json.Number:
+		return convertNumber(v, mode)
+	case int:
+		return convertNumber(json.Number(strconv.FormatInt(int64(v), 10)), mode)
+	case int64:
+		return convertNumber(json.Number(strconv.FormatInt(v, 10)), mode)
+	case uint64:
+		return convertNumber(json.Number(strconv.FormatUint(v, 10)), mode)
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) || mode == NumberModePreferInt {
+			// a float in the data stays a float, even if integral
+			return v
+		}
+		return convertNumber(json.Number(strconv.FormatFloat(v, 'g', -1, 64)), mode)
+	}
+	return v
+}
+
+// convertNumber converts the number according to the given mode.
+func convertNumber(number json.Number, mode NumberMode) interface{} {
+	switch mode {
+	case NumberModeAllFloat64:
+		f, _ := number.Float64()
+		return f
+	case NumberModePreferInt:
+		if !strings.ContainsAny(number.String(), ".eE") {
+			if i, err := strconv.ParseInt(number.String(), 10, 0); err == nil {
+				return int(i)
+			}
+		}
+		f, _ := number.Float64()
+		return f
+	default:
+		return number
+	}
+}
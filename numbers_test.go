@@ -0,0 +1,64 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWithNumberMode(t *testing.T) {
+	inputs := []string{
+		`{"int": 42, "float": 1.5, "integral": 2.0, "big": 9007199254740993, "list": [1, 2.5]}`,
+		"---\nint: 42\nfloat: 1.5\nintegral: 2.0\nbig: 9007199254740993\nlist: [1, 2.5]\n",
+		"toml:int = 42\nfloat = 1.5\nintegral = 2.0\nbig = 9007199254740993\nlist = [1, 2.5]\n",
+	}
+	expected := map[NumberMode]map[string]interface{}{
+		NumberModeAllFloat64: {
+			"int": float64(42), "float": 1.5, "integral": float64(2), "big": float64(9007199254740993), "list": []interface{}{float64(1), 2.5},
+		},
+		NumberModePreferInt: {
+			"int": 42, "float": 1.5, "integral": float64(2), "big": 9007199254740993, "list": []interface{}{1, 2.5},
+		},
+	}
+	for mode, values := range expected {
+		for _, input := range inputs {
+			for _, opts := range [][]Option{{WithNumberMode(mode)}, {WithNumberMode(mode), WithOrderedMaps(true)}} {
+				result, err := Unmarshal(input, opts...)
+				if err != nil {
+					t.Fatalf("error unmarshalling %q: %v", input, err)
+				}
+				for key, value := range values {
+					if actual, _ := GetPath(result, key); !reflect.DeepEqual(actual, value) {
+						t.Errorf("invalid %s in %v mode for %q: expected %v (%T), got %v (%T)", key, mode, input, value, value, actual, actual)
+					}
+				}
+			}
+		}
+	}
+	// literals are kept where the format has them
+	for i, integral := range []json.Number{"2.0", "2.0", "2"} {
+		result, err := Unmarshal(inputs[i], WithNumberMode(NumberModeAllJSONNumber))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", inputs[i], err)
+		}
+		for key, value := range map[string]interface{}{"int": json.Number("42"), "float": json.Number("1.5"), "integral": integral, "big": json.Number("9007199254740993")} {
+			if actual, _ := GetPath(result, key); actual != value {
+				t.Errorf("invalid %s in json.Number mode for %q: expected %v, got %v (%T)", key, inputs[i], value, actual, actual)
+			}
+		}
+	}
+	result, err := Unmarshal("---\na: .inf\nb: 0x10\n", WithNumberMode(NumberModeAllJSONNumber))
+	if err != nil {
+		t.Fatalf("error unmarshalling special numbers: %v", err)
+	}
+	if a, _ := GetPath(result, "a"); !math.IsInf(a.(float64), 1) {
+		t.Errorf("invalid infinity: %v", a)
+	}
+	if b, _ := GetPath(result, "b"); b != json.Number("16") {
+		t.Errorf("invalid hexadecimal number: %v (%T)", b, b)
+	}
+	if NumberModePreferInt.String() != "int" || NumberMode(9).String() != "NumberMode(9)" {
+		t.Errorf("invalid names: %v, %v", NumberModePreferInt, NumberMode(9))
+	}
+}
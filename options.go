@@ -0,0 +1,849 @@
+package rawdata
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// DefaultMaxFileSize is the default maximum size in bytes of the data that
+// can be read from a file, from standard input or from a remote URL.
+const DefaultMaxFileSize int64 = 16 * 1024 * 1024
+
+// DefaultMaxAliasExpansion is the default maximum number of nodes that the
+// aliases in YAML data can expand to (see WithMaxAliasExpansion).
+const DefaultMaxAliasExpansion = 1000000
+
+// Option is a functional option that customises the behaviour of Unmarshal,
+// UnmarshalInto, ReadContent and the other functions in this package; when
+// multiple options are provided they are applied in order, left to right, so
+// if the same setting is specified twice the last one wins.
+type Option func(*options)
+
+// options holds the settings that can be customised through Options.
+type options struct {
+	// maxFileSize is the maximum size in bytes of the data that can be read
+	// from a file, standard input or a remote URL; 0 means unlimited.
+	maxFileSize int64
+	// allowFileAccess is whether values referring to files ("@...") are
+	// accepted.
+	allowFileAccess bool
+	// strict is whether UnmarshalInto rejects keys in the input that do not
+	// match any field in the target.
+	strict bool
+	// expandEnv is whether environment variables references in the data are
+	// expanded before unmarshalling.
+	expandEnv bool
+	// allowScalars is whether inline scalar values (e.g. 42, true or hello)
+	// are accepted.
+	allowScalars bool
+	// includes is whether string values starting with '@' in the decoded
+	// data are replaced with the contents of the referenced files.
+	includes bool
+	// maxIncludeDepth is the maximum nesting level of includes; 0 means
+	// unlimited.
+	maxIncludeDepth int
+	// baseDir is the directory relative file references are resolved
+	// against; if empty, they are resolved against the current directory.
+	baseDir string
+	// fsys is the filesystem files are read from; if nil, files are read
+	// from the local filesystem.
+	fsys fs.FS
+	// writeFile writes files for MarshalToFile to the same filesystem as fsys,
+	// if it supports writing; if nil, files are written to the local
+	// filesystem.
+	writeFile func(filename string, data []byte, perm fs.FileMode) error
+	// followSymlinks is whether files that are symbolic links are accepted.
+	followSymlinks bool
+	// allowedRoot is the directory files must be in; if empty, files can be
+	// read from anywhere.
+	allowedRoot string
+	// orderedMaps is whether objects are returned as OrderedMaps instead of
+	// map[string]interface{}.
+	orderedMaps bool
+	// useNumber is whether numbers are returned as json.Numbers instead of
+	// float64s, ints and so on.
+	useNumber bool
+	// extensions maps file extensions (lower case, with the leading dot) to
+	// formats, overriding the built-in mappings.
+	extensions map[string]Format
+	// allowComments is whether comments are allowed in JSON data.
+	allowComments bool
+	// allowTrailingCommas is whether trailing commas are allowed in JSON
+	// arrays and objects.
+	allowTrailingCommas bool
+	// csvDelimiter is the field delimiter in CSV data.
+	csvDelimiter rune
+	// csvHeader is whether the first record in CSV data is a header.
+	csvHeader bool
+	// allowEmpty is whether empty content is unmarshalled as nil instead of
+	// being an error.
+	allowEmpty bool
+	// maxDepth is the maximum nesting depth of the data; 0 means unlimited.
+	maxDepth int
+	// maxAliasExpansion is the maximum number of nodes that YAML aliases can
+	// expand to; 0 means unlimited.
+	maxAliasExpansion int
+	// invalidLine is called for each line of NDJSON data that cannot be
+	// decoded; if nil, decoding fails at the first one.
+	invalidLine func(err *DecodeError) error
+	// timeLayouts are the layouts accepted for times in addition to
+	// DefaultTimeLayouts; if nil, times are left to the YAML library.
+	timeLayouts []string
+	// parseTimestamps is whether strings that look like times are converted
+	// to time.Time by Unmarshal.
+	parseTimestamps bool
+	// sniffUnknownExtensions is whether the format of files with no or an
+	// unknown extension is detected from their contents.
+	sniffUnknownExtensions bool
+	// implicitYAML is whether data whose format cannot be sniffed is taken
+	// to be YAML.
+	implicitYAML bool
+	// autoDetect is whether data whose format cannot be sniffed is tried as
+	// JSON and then as YAML.
+	autoDetect bool
+	// httpClient is the client used to fetch remote documents; if nil,
+	// HTTPClient is used.
+	httpClient *http.Client
+	// template is whether the data is rendered as a text/template before
+	// being decoded, with templateData as its data.
+	template     bool
+	templateData interface{}
+	// templateFuncs are the custom functions available to templates.
+	templateFuncs template.FuncMap
+	// verifyFormat is whether the data is checked against the format
+	// detected from the file extension or the content type.
+	verifyFormat bool
+	// defaults is the value the data is merged on top of by UnmarshalInto,
+	// if any.
+	defaults interface{}
+	// separator is the separator between keys in the paths used by Flatten.
+	separator string
+	// caseInsensitivePaths is whether the keys in the paths used by GetPath
+	// are matched regardless of case.
+	caseInsensitivePaths bool
+	// preserveComments is whether Unmarshal returns YAML data as a Document,
+	// keeping its comments.
+	preserveComments bool
+	// yamlBoolCompat is whether the YAML 1.1 booleans (e.g. yes and off) are
+	// decoded as booleans instead of strings.
+	yamlBoolCompat bool
+	// requireFile is whether values must refer to files ("@...").
+	requireFile bool
+	// allowEmptyGlob is whether glob patterns matching no files result in an
+	// empty array instead of an error.
+	allowEmptyGlob bool
+	// mergeDirectory is whether references to directories read and merge
+	// all the files in them.
+	mergeDirectory bool
+	// skipUnsupportedFiles is whether files in unsupported formats are
+	// skipped when merging directories, instead of being an error.
+	skipUnsupportedFiles bool
+	// contentTransform is applied to the data after it is read and before
+	// it is decoded, if set.
+	contentTransform func(format Format, content []byte) ([]byte, error)
+	// concurrency is the maximum number of values loaded at the same time
+	// by UnmarshalMerged; 0 or 1 means one at a time.
+	concurrency int
+	// unknownKey is called by UnmarshalInto with the path of each key in the
+	// data that does not match any field in the target, if set.
+	unknownKey func(path string)
+	// yamlMergeKeys is whether YAML merge keys ("<<") are expanded by this
+	// package rather than by the YAML library.
+	yamlMergeKeys bool
+	// indent is the indentation of the output of Marshal.
+	indent string
+	// compact is whether the output of Marshal is as compact as possible.
+	compact bool
+	// numberMode is the representation of numbers in generic values.
+	numberMode NumberMode
+	// decodePath is whether file paths are percent-decoded before use.
+	decodePath bool
+	// decodeHooks are called by UnmarshalInto to convert the values in the
+	// data for the fields they are bound for, in order.
+	decodeHooks []func(from, to reflect.Type, data interface{}) (interface{}, error)
+	// allowRemote is whether values referring to remote URLs or to URIs with
+	// a registered scheme are accepted.
+	allowRemote bool
+	// schema is the JSON Schema the data is validated against, if any.
+	schema []byte
+	// cache is where the contents of files are cached, if anywhere.
+	cache Cache
+	// ctx is the context of the call, if any (see UnmarshalContext).
+	ctx context.Context
+}
+
+// newOptions returns the default options, as modified by the given Options.
+func newOptions(opts ...Option) *options {
+	o := &options{
+		maxFileSize:          DefaultMaxFileSize,
+		maxAliasExpansion:    DefaultMaxAliasExpansion,
+		allowFileAccess:      true,
+		allowRemote:          true,
+		followSymlinks:       true,
+		csvDelimiter:         ',',
+		csvHeader:            true,
+		skipUnsupportedFiles: true,
+		separator:            ".",
+		indent:               "  ",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// context returns the context of the call, or a background context if none
+// was provided.
+func (o *options) context() context.Context {
+	if o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// remoteAccess returns whether remote URLs and URIs with a registered scheme
+// can be read: file access must be allowed too, since disabling it is meant
+// to confine the input to the values themselves.
+func (o *options) remoteAccess() bool {
+	return o.allowFileAccess && o.allowRemote
+}
+
+// filesystem returns the filesystem files should be read from.
+func (o *options) filesystem() fs.FS {
+	if o.fsys != nil {
+		return o.fsys
+	}
+	return osFS{}
+}
+
+// WithMaxFileSize sets the maximum size in bytes of the data that can be
+// read from a file (after decompression, for gzipped files), from standard
+// input or from a remote URL; exceeding it results in ErrFileTooLarge. The
+// default is DefaultMaxFileSize; a value of 0 means that there is no limit.
+func WithMaxFileSize(size int64) Option {
+	return func(o *options) {
+		o.maxFileSize = size
+	}
+}
+
+// WithAllowFileAccess sets whether values referring to files on the local
+// filesystem (e.g. "@myfile.json", or "@-" for standard input) are accepted;
+// file access is allowed by default, disable it when the input values come
+// from untrusted sources, so that any such reference (including includes, see
+// WithIncludes) is rejected with ErrFileAccessDisabled. Disabling it also
+// disables remote URLs and registered schemes, as WithAllowRemote does, so
+// that only inline values, data URIs and in-memory sources (see
+// RegisterSource) are read.
+func WithAllowFileAccess(allow bool) Option {
+	return func(o *options) {
+		o.allowFileAccess = allow
+	}
+}
+
+// WithStrict sets whether UnmarshalInto should reject input containing keys
+// that do not map to any field in the target object, instead of silently
+// ignoring them; it has no effect on Unmarshal, which has no schema to check
+// the input against.
+func WithStrict(strict bool) Option {
+	return func(o *options) {
+		o.strict = strict
+	}
+}
+
+// WithEnvExpansion sets whether references to environment variables in the
+// data (either $VAR or ${VAR}) are replaced with their values before the data
+// is unmarshalled; see expandEnv for the supported syntax.
+func WithEnvExpansion(expand bool) Option {
+	return func(o *options) {
+		o.expandEnv = expand
+	}
+}
+
+// WithAllowScalars sets whether inline data consisting of a bare scalar
+// value (e.g. 42, true or hello) is accepted and unmarshalled as a YAML
+// scalar, yielding an int, a bool, a string and so on; by default such
+// values are rejected as unrecognisable.
+func WithAllowScalars(allow bool) Option {
+	return func(o *options) {
+		o.allowScalars = allow
+	}
+}
+
+// WithIncludes sets whether string values in the unmarshalled data that start
+// with '@' (e.g. tls: "@shared/tls.yaml") are treated as references to other
+// files, which are unmarshalled in turn and substituted in place of the string;
+// relative references are resolved against the directory of the including
+// file, and cyclic references are an error. Values starting with an escaped
+// '@' ("@@" or "\@") are not references: the escape is removed and the rest
+// is kept as it is. Includes are only resolved by the functions returning
+// generic values (e.g. Unmarshal), not by UnmarshalInto.
+func WithIncludes(includes bool) Option {
+	return func(o *options) {
+		o.includes = includes
+	}
+}
+
+// WithMaxIncludeDepth sets the maximum nesting level of includes (see
+// WithIncludes); the default value of 0 means that there is no limit other
+// than the detection of cycles.
+func WithMaxIncludeDepth(depth int) Option {
+	return func(o *options) {
+		o.maxIncludeDepth = depth
+	}
+}
+
+// WithBaseDir sets the directory against which relative file references
+// (e.g. "@sub/myfile.json") are resolved, instead of the current working
+// directory of the process; absolute paths are not affected.
+func WithBaseDir(dir string) Option {
+	return func(o *options) {
+		o.baseDir = dir
+	}
+}
+
+// WithFS sets the filesystem file references are read from, e.g. an embed.FS
+// holding configuration files compiled into the binary, or an fstest.MapFS in
+// unit tests; paths are converted to the slash-separated, unrooted form fs.FS
+// expects (e.g. "@./config/app.yaml" becomes "config/app.yaml"). By default,
+// files are read from the local filesystem. WithFS and WithAferoFs override
+// each other: if both are given, the last one wins.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+		o.writeFile = nil
+	}
+}
+
+// WithAllowedRoot confines file references to the given directory: any file
+// whose path, once cleaned and with symbolic links evaluated, falls outside
+// of it (e.g. "@../../etc/passwd") is rejected with ErrPathEscape. By default
+// files can be read from anywhere.
+func WithAllowedRoot(root string) Option {
+	return func(o *options) {
+		o.allowedRoot = root
+	}
+}
+
+// WithOrderedMaps sets whether objects are unmarshalled into OrderedMaps,
+// which preserve the order of the keys in the input, instead of into plain
+// map[string]interface{}; it has no effect on UnmarshalInto.
+func WithOrderedMaps(ordered bool) Option {
+	return func(o *options) {
+		o.orderedMaps = ordered
+	}
+}
+
+// WithUseNumber sets whether numbers are unmarshalled as json.Numbers, which
+// preserve their original representation, instead of float64s (for JSON) or
+// ints and float64s (for YAML and TOML); this avoids losing precision with
+// large integer IDs, which do not fit in a float64. YAML numbers that have no
+// JSON equivalent (e.g. .inf or 0x1F) are left as they are.
+func WithUseNumber(use bool) Option {
+	return func(o *options) {
+		o.useNumber = use
+	}
+}
+
+// WithAllowEmpty sets whether empty or whitespace-only data is accepted: if
+// it is, Unmarshal returns a nil value and UnmarshalInto leaves the target
+// untouched, otherwise (the default) they return ErrEmptyContent.
+func WithAllowEmpty(allow bool) Option {
+	return func(o *options) {
+		o.allowEmpty = allow
+	}
+}
+
+// WithAllowComments sets whether JSON data may contain line ("// ...") and
+// block ("/* ... */") comments, as in JSONC files; comments are stripped
+// before decoding. By default only strict JSON is accepted.
+func WithAllowComments(allow bool) Option {
+	return func(o *options) {
+		o.allowComments = allow
+	}
+}
+
+// WithAllowTrailingCommas sets whether JSON arrays and objects may have a
+// trailing comma after their last element (e.g. [1, 2, 3,]); such commas are
+// stripped before decoding. By default only strict JSON is accepted.
+func WithAllowTrailingCommas(allow bool) Option {
+	return func(o *options) {
+		o.allowTrailingCommas = allow
+	}
+}
+
+// WithExtensionMap adds custom mappings from file extensions to formats
+// (e.g. ".cfg" to FormatYAML), which are consulted before the built-in ones
+// and can therefore also override them; extensions are case insensitive and
+// the leading dot is optional. The option can be used more than once.
+func WithExtensionMap(extensions map[string]Format) Option {
+	return func(o *options) {
+		if o.extensions == nil {
+			o.extensions = map[string]Format{}
+		}
+		for ext, format := range extensions {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			o.extensions[ext] = format
+		}
+	}
+}
+
+// WithCSVDelimiter sets the field delimiter for CSV data (e.g. ';' or '\t');
+// the default is a comma.
+func WithCSVDelimiter(delimiter rune) Option {
+	return func(o *options) {
+		o.csvDelimiter = delimiter
+	}
+}
+
+// WithCSVHeader sets whether the first record in CSV data is a header: if it
+// is (the default), each following record is unmarshalled into an object
+// keyed by the column names in the header, otherwise each record is
+// unmarshalled into an array of fields.
+func WithCSVHeader(header bool) Option {
+	return func(o *options) {
+		o.csvHeader = header
+	}
+}
+
+// WithSchema sets a JSON Schema (draft-07 unless specified otherwise via the
+// "$schema" keyword) that the data is validated against after decoding, by
+// Unmarshal and UnmarshalInto; if the data does not match, a *SchemaError
+// listing all the violations is returned. See also ValidateAgainstSchema.
+func WithSchema(schema []byte) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// WithCache sets a cache for the contents of files referenced via "@" (e.g.
+// a MemoryCache shared by all the calls in a long-running server), so that
+// unchanged files are not read again; a file is considered unchanged as long
+// as its modification time is the same. Inline values, standard input and
+// remote URLs are never cached. The same cache should not be used with
+// different filesystems (see WithFS).
+func WithCache(cache Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithFollowSymlinks sets whether file references may point to symbolic
+// links on the local filesystem: if they may (the default), links are
+// followed, but the file they resolve to must still be inside the allowed
+// root, if any (see WithAllowedRoot); if they may not, they are rejected with
+// ErrSymlink.
+func WithFollowSymlinks(follow bool) Option {
+	return func(o *options) {
+		o.followSymlinks = follow
+	}
+}
+
+// WithMaxDepth sets the maximum nesting depth of the data, i.e. of objects
+// and arrays within one another (e.g. {"a": [1]} has a depth of 2), as a
+// defence against adversarial input; data nested more deeply is rejected with
+// ErrMaxDepthExceeded. JSON and YAML are checked before they are decoded, the
+// other formats afterwards. The default value of 0 means that there is no
+// limit.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithMaxAliasExpansion sets the maximum number of nodes that the aliases in
+// YAML data can expand to, counting each alias as a copy of the node it refers
+// to along with all of its descendants; this is a defence against "billion
+// laughs" attacks, where a small document with nested aliases expands to a
+// huge value. Exceeding it results in ErrAliasBudgetExceeded, before any
+// value is built. The default is DefaultMaxAliasExpansion (1,000,000 nodes),
+// which is far more than reasonable data needs; a value of 0 means that there
+// is no limit.
+func WithMaxAliasExpansion(nodes int) Option {
+	return func(o *options) {
+		o.maxAliasExpansion = nodes
+	}
+}
+
+// WithInvalidLineHandler sets a function to be called for each line of NDJSON
+// data that cannot be decoded, with a DecodeError reporting its line number;
+// if the function returns nil the line is skipped, otherwise decoding stops
+// and the returned error is returned. By default, decoding fails at the first
+// invalid line.
+func WithInvalidLineHandler(handler func(err *DecodeError) error) Option {
+	return func(o *options) {
+		o.invalidLine = handler
+	}
+}
+
+// WithTimeLayouts sets the layouts (as used by time.Parse) accepted for times
+// in YAML data, in addition to DefaultTimeLayouts. When unmarshalling YAML into
+// a typed target, values that match any of them populate time.Time fields,
+// where otherwise only the forms of the YAML timestamp type would work; with
+// WithParseTimestamps, they also determine which strings are converted. Pass
+// nil to accept just the default layouts.
+func WithTimeLayouts(layouts []string) Option {
+	return func(o *options) {
+		o.timeLayouts = append([]string{}, layouts...)
+	}
+}
+
+// WithParseTimestamps sets whether Unmarshal converts strings that match any
+// of the accepted time layouts (see DefaultTimeLayouts and WithTimeLayouts)
+// into time.Time values, in data of any format; by default they are left as
+// strings.
+func WithParseTimestamps(parse bool) Option {
+	return func(o *options) {
+		o.parseTimestamps = parse
+	}
+}
+
+// WithSniffUnknownExtensions sets whether the format of files with no
+// extension (e.g. "@Procfile") or an unrecognised one is detected from their
+// contents, instead of being rejected with ErrUnsupportedFormat: besides the
+// leading characters used for inline data ("---", "{", "[" and "<"), the data
+// is tried as JSON and then as a YAML mapping or sequence. It is disabled by
+// default.
+func WithSniffUnknownExtensions(sniff bool) Option {
+	return func(o *options) {
+		o.sniffUnknownExtensions = sniff
+	}
+}
+
+// WithAutoDetect sets whether inline data (or data from standard input) whose
+// format cannot be detected from its leading characters is tried as JSON and
+// then as YAML, instead of being rejected with ErrUnrecognisedInline; the
+// data is taken to be in the first format it can be decoded from, so that
+// e.g. "name: test" is YAML without the leading "---". If neither works, the
+// error reports both failures. It is disabled by default.
+func WithAutoDetect(auto bool) Option {
+	return func(o *options) {
+		o.autoDetect = auto
+	}
+}
+
+// WithImplicitYAML sets whether inline data (or data from standard input) that
+// does not start like JSON or XML is taken to be YAML even without the leading
+// "---", so that e.g. "name: test\nport: 8080" can be unmarshalled; errors in
+// such data are then reported by the YAML decoder. Bare scalars are still
+// subject to WithAllowScalars. It is disabled by default, so that such data is
+// rejected with ErrUnrecognisedInline.
+func WithImplicitYAML(implicit bool) Option {
+	return func(o *options) {
+		o.implicitYAML = implicit
+	}
+}
+
+// WithDefaults sets a value (typically a struct of the same type as the
+// target, or a map) holding the defaults for UnmarshalInto: the data is
+// deep-merged on top of the defaults before being decoded into the target, as
+// with Merge, so that objects are merged key by key, whereas scalars and
+// arrays in the data (including explicit nulls) replace the defaults as a
+// whole; anything not set in the data keeps its default value. The defaults
+// are encoded in the format of the data (in JSON for CSV, properties and
+// NDJSON data), so the same struct tags apply to them; other formats are not
+// supported. The defaults are not modified.
+func WithDefaults(defaults interface{}) Option {
+	return func(o *options) {
+		o.defaults = defaults
+	}
+}
+
+// WithVerifyFormat sets whether the data is checked against the format
+// detected from the file extension (or, for remote data, the content type)
+// before being decoded, so that mislabelled files (e.g. a "config.json" that
+// actually contains YAML) are rejected with ErrFormatMismatch instead of
+// producing confusing errors or, worse, wrong results. The check only looks at
+// how the data starts: for instance JSON must start with an object, an array,
+// a string, a number, true, false or null, and cannot start with "---". It is
+// disabled by default, and does not apply to formats forced by a prefix.
+func WithVerifyFormat(verify bool) Option {
+	return func(o *options) {
+		o.verifyFormat = verify
+	}
+}
+
+// WithHTTPClient sets the client used to fetch remote documents, instead of
+// the package-level HTTPClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithTemplate sets whether the data is rendered as a text/template before its
+// format is detected and it is decoded, with the given value (e.g. a map or a
+// struct) as the data of the template, so that e.g. "region: {{ .Region }}"
+// can be filled in at load time, with conditionals and loops also available.
+// Referring to a missing key of a map is an error. Errors in parsing or
+// executing the template are returned as a *TemplateError. Templating is
+// disabled by default.
+func WithTemplate(data interface{}) Option {
+	return func(o *options) {
+		o.template = true
+		o.templateData = data
+	}
+}
+
+// WithTemplateFuncs adds custom functions to those available to templates (see
+// WithTemplate); it can be passed more than once.
+func WithTemplateFuncs(funcs template.FuncMap) Option {
+	return func(o *options) {
+		if o.templateFuncs == nil {
+			o.templateFuncs = template.FuncMap{}
+		}
+		for name, fn := range funcs {
+			o.templateFuncs[name] = fn
+		}
+	}
+}
+
+// WithLimits sets all the limits on the data at once, replacing any set
+// before (including the defaults) with the values in the given Limits, where 0
+// means that there is no limit; the individual options (e.g. WithMaxDepth) can
+// still be passed after it to override single limits.
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.maxFileSize = limits.MaxFileSize
+		o.maxDepth = limits.MaxDepth
+		o.maxAliasExpansion = limits.MaxAliasExpansion
+		o.maxIncludeDepth = limits.MaxIncludeDepth
+	}
+}
+
+// WithSeparator sets the separator between the keys in the paths produced by
+// Flatten, e.g. "/" or "_"; the default is ".". An empty separator resets it
+// to the default.
+func WithSeparator(separator string) Option {
+	return func(o *options) {
+		if separator == "" {
+			separator = "."
+		}
+		o.separator = separator
+	}
+}
+
+// WithCaseInsensitivePaths sets whether GetPath matches the keys in the path
+// regardless of case (e.g. "Server.Port" finding "server.port"); an exact
+// match is always preferred, and a key that matches more than one entry in an
+// object is not found.
+func WithCaseInsensitivePaths(insensitive bool) Option {
+	return func(o *options) {
+		o.caseInsensitivePaths = insensitive
+	}
+}
+
+// WithPreserveComments sets whether Unmarshal returns YAML data as a Document
+// instead of a plain value, so that it can be modified and written back with
+// Marshal without losing its comments; the data is still validated against
+// the schema, if any, but includes are not resolved and timestamps are not
+// parsed in the Document. Data in other formats is not affected.
+func WithPreserveComments(preserve bool) Option {
+	return func(o *options) {
+		o.preserveComments = preserve
+	}
+}
+
+// WithYAMLBoolCompat sets whether unquoted YAML 1.1 boolean tokens are
+// decoded as booleans instead of strings, as they are in YAML 1.2, to ease
+// the migration of data written for older tools: the tokens are y, yes, on
+// (true) and n, no, off (false), each in lower case, capitalised (e.g. Yes)
+// or upper case (e.g. YES). This affects generic values, e.g. those returned
+// by Unmarshal or decoded into interface{} fields by UnmarshalInto, since the
+// YAML library already accepts these tokens for bool fields; quoted strings
+// and object keys are left alone, as is data in any format other than YAML.
+func WithYAMLBoolCompat(compat bool) Option {
+	return func(o *options) {
+		o.yamlBoolCompat = compat
+	}
+}
+
+// WithRequireFile sets whether values must refer to files (e.g. "@app.yaml"),
+// so that data cannot bypass the review of the files by being passed inline;
+// anything else, including standard input ("@-"), URLs, data URIs and escaped
+// inline data, results in ErrInlineNotAllowed, whereas in-memory sources (see
+// RegisterSource) are accepted, since they are set by the program itself. It
+// is the opposite of disabling file access with WithAllowFileAccess.
+func WithRequireFile(require bool) Option {
+	return func(o *options) {
+		o.requireFile = require
+	}
+}
+
+// WithAllowEmptyGlob sets whether a glob pattern (e.g. "@conf.d/*.yaml")
+// that matches no files results in an empty array, in JSON unless another
+// format is forced with a prefix, instead of ErrFileNotFound.
+func WithAllowEmptyGlob(allow bool) Option {
+	return func(o *options) {
+		o.allowEmptyGlob = allow
+	}
+}
+
+// WithMergeDirectory sets whether a reference to a directory (e.g.
+// "@conf.d/") reads all the files directly in it, in sorted filename order,
+// and deep-merges them into a single document, as in the common "conf.d"
+// pattern for drop-in configuration files, instead of being an error
+// (ErrIsDirectory). Files coming later in the order take precedence: their
+// scalars and arrays replace those in the files before them, whereas objects
+// are merged key by key (see Merge), so that e.g. "20-local.yaml" can
+// override single settings in "10-defaults.json". Files in formats that are
+// not supported are skipped (see WithSkipUnsupportedFiles), and so are empty
+// files and subdirectories.
+func WithMergeDirectory(merge bool) Option {
+	return func(o *options) {
+		o.mergeDirectory = merge
+	}
+}
+
+// WithSkipUnsupportedFiles sets whether the files in formats that are not
+// supported (e.g. README.md) are skipped when merging a directory (see
+// WithMergeDirectory), which is the default, or result in an error
+// (ErrUnsupportedFormat).
+func WithSkipUnsupportedFiles(skip bool) Option {
+	return func(o *options) {
+		o.skipUnsupportedFiles = skip
+	}
+}
+
+// WithContentTransform sets a function that is applied to the data after it
+// has been read and its format detected, and before it is decoded, e.g. to
+// decrypt files encrypted with SOPS, without this package depending on any
+// cryptographic library; it receives the format of the data and returns the
+// data to be decoded in its place, which must be in the same format, or an
+// error that aborts the decoding. It runs before environment variables are
+// expanded (see WithEnvExpansion); for glob patterns and merged directories,
+// it is applied once to the combined data.
+func WithContentTransform(transform func(format Format, content []byte) ([]byte, error)) Option {
+	return func(o *options) {
+		o.contentTransform = transform
+	}
+}
+
+// WithConcurrency sets the maximum number of values that are loaded at the
+// same time when merging several of them (see UnmarshalMergedContext and
+// Decoder.UnmarshalMerged), which speeds up loading them from several remote
+// servers; the results are still merged in the order of the values, and the
+// first error cancels the loading of the values still in progress. The
+// default is 1, i.e. the values are loaded one at a time.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithUnknownKeyHandler sets a function that UnmarshalInto calls with the
+// path (e.g. "server.0.hostname", see WithSeparator) of each key in the data
+// that does not match any field in the target, and that would therefore be
+// ignored, e.g. to warn about deprecated settings while still loading them;
+// keys are reported in sorted order, before the data is decoded, and nothing
+// changes in the decoding itself (see WithStrict to reject them). Keys are
+// matched to fields as by the library decoding the data, so XML and
+// registered formats are not checked.
+func WithUnknownKeyHandler(handler func(path string)) Option {
+	return func(o *options) {
+		o.unknownKey = handler
+	}
+}
+
+// WithYAMLMergeKeys sets whether the merge keys ("<<") in YAML data are
+// expanded by this package when decoding into generic values (e.g. by
+// Unmarshal), instead of being left to the YAML library, whose handling of
+// precedence varies across versions: the keys set explicitly in a mapping
+// always take precedence over the merged ones, wherever the merge key is, and
+// when merging a sequence of mappings (e.g. "<<: [*a, *b]") the keys in the
+// earlier mappings take precedence over those in the later ones, as in the
+// YAML specification. Merged mappings can contain merge keys in turn; merging
+// anything other than a mapping or a sequence of mappings is an error. This is
+// always the case with WithOrderedMaps and WithUseNumber.
+func WithYAMLMergeKeys(expand bool) Option {
+	return func(o *options) {
+		o.yamlMergeKeys = expand
+	}
+}
+
+// WithIndent sets the string used to indent each level of the output of
+// Marshal, Convert and the like (e.g. "\t" or four spaces), which is two
+// spaces by default; YAML only supports indenting with 2 to 9 spaces, so any
+// other indentation results in the default one.
+func WithIndent(indent string) Option {
+	return func(o *options) {
+		o.indent = indent
+	}
+}
+
+// WithCompact sets whether the output of Marshal, Convert and the like is as
+// compact as possible, i.e. on a single line for JSON and with no indentation
+// for TOML; YAML has no compact form, so it is not affected.
+func WithCompact(compact bool) Option {
+	return func(o *options) {
+		o.compact = compact
+	}
+}
+
+// WithNumberMode sets the representation of the numbers in the values
+// returned by Unmarshal and the like, so that the same data yields the same
+// values whatever its format: all float64 (NumberModeAllFloat64), all
+// json.Number (NumberModeAllJSONNumber) or int for integers and float64 for
+// the rest (NumberModePreferInt); see the modes for the details. It takes
+// precedence over WithUseNumber, and has no effect on UnmarshalInto, where
+// the types of the fields decide.
+func WithNumberMode(mode NumberMode) Option {
+	return func(o *options) {
+		o.numberMode = mode
+	}
+}
+
+// WithDecodeHook adds a function that UnmarshalInto calls to convert values
+// that the library decoding the data cannot convert by itself, e.g. strings
+// into time.Duration in JSON data: each value in the data is passed to it
+// along with its type (from) and the type of the field it is bound for (to,
+// without any pointers), and the value it returns replaces the original,
+// unless it is an error. Values are visited from the root down, so that the
+// contents of objects and arrays are visited after, and as returned by, the
+// hook; null values are not visited, nor are the contents of values bound for
+// types that decode themselves (e.g. via encoding.TextUnmarshaler). Hooks
+// compose: they are called in the order they were added, each with the value
+// returned by the previous one, so a hook that has nothing to convert must
+// return the data it is passed unchanged. The converted values are encoded
+// back into the format of the data (or into JSON, for the formats decoded via
+// JSON), so they must survive the round trip, as values of the target type
+// normally do; XML and registered formats are not affected.
+func WithDecodeHook(hook func(from, to reflect.Type, data interface{}) (interface{}, error)) Option {
+	return func(o *options) {
+		if hook != nil {
+			o.decodeHooks = append(o.decodeHooks, hook)
+		}
+	}
+}
+
+// WithDecodePath sets whether the paths in file references are percent-decoded
+// before they are used, e.g. "@my%20config.yaml" for "my config.yaml" or
+// "@conf%2Fapp.json" for "conf/app.json", for paths that have been through
+// URL-encoded transports; only the path after the "@" is decoded, never inline
+// data (escaped or not), and "file://" URIs, whose paths are always decoded,
+// are not decoded twice. A path with an invalid percent-encoding is an error.
+func WithDecodePath(decode bool) Option {
+	return func(o *options) {
+		o.decodePath = decode
+	}
+}
+
+// WithAllowRemote sets whether values referring to remote URLs (e.g.
+// "https://example.com/app.json") or to URIs with a registered scheme (see
+// RegisterScheme) are accepted; they are allowed by default, unless file
+// access is disabled (see WithAllowFileAccess). Disable it when the input
+// values come from untrusted sources but may still refer to local files, so
+// that the values cannot make the application send requests on their behalf;
+// any such reference (including includes, see WithIncludes) is rejected with
+// ErrRemoteAccessDisabled.
+func WithAllowRemote(allow bool) Option {
+	return func(o *options) {
+		o.allowRemote = allow
+	}
+}
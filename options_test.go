@@ -0,0 +1,364 @@
+package rawdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWithMaxFileSize(t *testing.T) {
+	if _, err := Unmarshal("@./test/struct.json", WithMaxFileSize(10)); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("invalid error on file exceeding the maximum size: %v", err)
+	}
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader(`["one", "two", "three"]`)
+	if _, err := Unmarshal("@-", WithMaxFileSize(10)); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("invalid error on standard input exceeding the maximum size: %v", err)
+	}
+	if _, err := Unmarshal("@./test/large.json.gz", WithMaxFileSize(1024)); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("invalid error on decompressed data exceeding the maximum size: %v", err)
+	}
+	if _, err := Unmarshal("@./test/struct.json", WithMaxFileSize(1024)); err != nil {
+		t.Fatalf("error unmarshalling file within the maximum size: %v", err)
+	}
+	// options compose left to right: the last one wins
+	if _, err := Unmarshal("@./test/struct.json", WithMaxFileSize(10), WithMaxFileSize(0)); err != nil {
+		t.Fatalf("error unmarshalling file with unlimited size: %v", err)
+	}
+}
+
+func TestWithAllowFileAccess(t *testing.T) {
+	for _, input := range []string{"@./test/struct.json", "json:@./test/struct.conf", "@-", "@./test/*.json"} {
+		if _, err := Unmarshal(input, WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+			t.Fatalf("invalid error on file access when disabled: %v", err)
+		}
+		if err := UnmarshalInto(input, &s{}, WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+			t.Fatalf("invalid error on file access when disabled: %v", err)
+		}
+	}
+	if _, err := Unmarshal(`{"tls": "@./test/include/shared/tls.yaml"}`, WithIncludes(true), WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Fatalf("invalid error on include when file access disabled: %v", err)
+	}
+	if _, err := Unmarshal(`{"name": "John"}`, WithAllowFileAccess(false)); err != nil {
+		t.Fatalf("error unmarshalling inline value with file access disabled: %v", err)
+	}
+}
+
+func TestWithAllowRemote(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`["one", "two"]`))
+	}))
+	defer server.Close()
+	RegisterScheme("remotetest", func(ctx context.Context, uri string) (Format, []byte, error) {
+		requests++
+		return FormatJSON, []byte(`["one", "two"]`), nil
+	})
+	defer func() {
+		schemesLock.Lock()
+		delete(schemes, "remotetest")
+		schemesLock.Unlock()
+	}()
+
+	for _, disabled := range []Option{WithAllowRemote(false), WithAllowFileAccess(false)} {
+		for _, input := range []string{server.URL + "/array.json", "remotetest://bucket/array"} {
+			if _, err := Unmarshal(input, disabled); !errors.Is(err, ErrRemoteAccessDisabled) {
+				t.Errorf("invalid error on %q when remote access is disabled: %v", input, err)
+			}
+			if err := UnmarshalStream(input, func(int, interface{}) error { return nil }, disabled); !errors.Is(err, ErrRemoteAccessDisabled) {
+				t.Errorf("invalid error streaming %q when remote access is disabled: %v", input, err)
+			}
+			if _, err := DetectFormat(input, disabled); !errors.Is(err, ErrRemoteAccessDisabled) {
+				t.Errorf("invalid error detecting the format of %q when remote access is disabled: %v", input, err)
+			}
+		}
+		// data URIs are part of the value, not fetched
+		if _, err := Unmarshal("data:application/json,[1,2]", disabled); err != nil {
+			t.Errorf("error unmarshalling data URI when remote access is disabled: %v", err)
+		}
+	}
+	if requests != 0 {
+		t.Fatalf("%d requests sent when remote access is disabled", requests)
+	}
+	if _, err := Unmarshal("@./test/struct.json", WithAllowRemote(false)); err != nil {
+		t.Fatalf("error reading a file when remote access is disabled: %v", err)
+	}
+	if _, err := Unmarshal(server.URL+"/array.json", WithAllowRemote(false), WithAllowRemote(true)); err != nil || requests != 1 {
+		t.Fatalf("error reading a URL when remote access is enabled: %v", err)
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	inputs := []string{
+		`{"naem": "John", "surname": "Doe", "age": 23}`,
+		"---\nnaem: John\nsurname: Doe\nage: 23\n",
+	}
+	for _, input := range inputs {
+		if err := UnmarshalInto(input, &s{}); err != nil {
+			t.Fatalf("error unmarshalling in non-strict mode: %v", err)
+		}
+		err := UnmarshalInto(input, &s{}, WithStrict(true))
+		if err == nil {
+			t.Fatal("no error on unknown field in strict mode")
+		}
+		if !strings.Contains(err.Error(), "naem") {
+			t.Fatalf("error does not name the unknown field: %v", err)
+		}
+	}
+}
+
+func TestWithAllowScalars(t *testing.T) {
+	for input, expected := range map[string]interface{}{
+		"42":        42,
+		"true":      true,
+		"hello":     "hello",
+		"3.5":       3.5,
+		`"quoted"`:  "quoted",
+		" spaced  ": "spaced",
+	} {
+		if _, err := Unmarshal(input); err == nil {
+			t.Fatalf("no error on inline scalar %q without option", input)
+		}
+		result, err := Unmarshal(input, WithAllowScalars(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if result != expected {
+			t.Errorf("error unmarshalling %q: expected %v (type %T), got %v (type %T)", input, expected, expected, result, result)
+		}
+	}
+	// mappings without the leading '---' are not scalars
+	if _, err := Unmarshal("name: John", WithAllowScalars(true)); err == nil {
+		t.Fatal("no error on inline YAML mapping without leading '---'")
+	}
+	var port int
+	if err := UnmarshalInto("8080", &port, WithAllowScalars(true)); err != nil || port != 8080 {
+		t.Fatalf("invalid scalar unmarshalled: %v (error: %v)", port, err)
+	}
+}
+
+func TestWithBaseDir(t *testing.T) {
+	result := &s{}
+	if err := UnmarshalInto("@struct.json", result, WithBaseDir("./test")); err != nil {
+		t.Fatalf("error unmarshalling with base directory: %v", err)
+	}
+	if result.Name != "John" {
+		t.Fatalf("invalid value unmarshalled with base directory: %+v", result)
+	}
+	abs, err := filepath.Abs("./test/struct.yaml")
+	if err != nil {
+		t.Fatalf("error getting absolute path: %v", err)
+	}
+	if err := UnmarshalInto("@"+abs, result, WithBaseDir("/nonexisting")); err != nil {
+		t.Fatalf("error unmarshalling absolute path with base directory: %v", err)
+	}
+	if _, err := Unmarshal("@app.yaml", WithBaseDir("./test/include"), WithIncludes(true)); err != nil {
+		t.Fatalf("error unmarshalling with base directory and includes: %v", err)
+	}
+	if _, err := Unmarshal("@struct.json"); err == nil {
+		t.Fatal("no error on relative path without base directory")
+	}
+}
+
+func TestDefaultMaxFileSize(t *testing.T) {
+	if o := newOptions(); o.maxFileSize != DefaultMaxFileSize {
+		t.Fatalf("invalid default maximum file size: expected %d, got %d", DefaultMaxFileSize, o.maxFileSize)
+	}
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader("[" + strings.Repeat(" ", int(DefaultMaxFileSize)) + "]")
+	if _, err := Unmarshal("@-"); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("invalid error on standard input exceeding the default maximum size: %v", err)
+	}
+	stdin = strings.NewReader("[" + strings.Repeat(" ", int(DefaultMaxFileSize)) + "]")
+	if _, err := Unmarshal("@-", WithMaxFileSize(0)); err != nil {
+		t.Fatalf("error on unlimited size: %v", err)
+	}
+}
+
+func TestWithUseNumber(t *testing.T) {
+	for _, input := range []string{
+		`{"id": 123456789012345678, "ratio": 1e3}`,
+		"---\nid: 123456789012345678\nratio: 1e3\n",
+	} {
+		for _, ordered := range []bool{false, true} {
+			result, err := Unmarshal(input, WithUseNumber(true), WithOrderedMaps(ordered))
+			if err != nil {
+				t.Fatalf("error unmarshalling %q: %v", input, err)
+			}
+			var id, ratio interface{}
+			if m, ok := result.(*OrderedMap); ok {
+				id, _ = m.Get("id")
+				ratio, _ = m.Get("ratio")
+			} else {
+				id, ratio = result.(map[string]interface{})["id"], result.(map[string]interface{})["ratio"]
+			}
+			if id != json.Number("123456789012345678") {
+				t.Errorf("invalid number for %q: %v (type %T)", input, id, id)
+			}
+			if ratio != json.Number("1e3") {
+				t.Errorf("invalid number for %q: %v (type %T)", input, ratio, ratio)
+			}
+		}
+	}
+
+	result, err := Unmarshal("toml:id = 1234567890123456789\nratio = 0.5", WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling TOML: %v", err)
+	}
+	if id := result.(map[string]interface{})["id"]; id != json.Number("1234567890123456789") {
+		t.Errorf("invalid number for TOML: %v (type %T)", id, id)
+	}
+
+	// YAML numbers without a JSON representation are left untouched
+	result, err = Unmarshal("---\n- 0x1F\n- .inf\n", WithUseNumber(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling YAML: %v", err)
+	}
+	if array := result.([]interface{}); array[0] != 31 {
+		t.Errorf("invalid hexadecimal number: %v (type %T)", array[0], array[0])
+	}
+
+	target := struct {
+		ID interface{} `json:"id"`
+	}{}
+	if err := UnmarshalInto(`{"id": 123456789012345678}`, &target, WithUseNumber(true)); err != nil {
+		t.Fatalf("error unmarshalling into struct: %v", err)
+	}
+	if target.ID != json.Number("123456789012345678") {
+		t.Errorf("invalid number in struct: %v (type %T)", target.ID, target.ID)
+	}
+}
+
+func TestWithExtensionMap(t *testing.T) {
+	if _, err := Unmarshal("@./test/server.cfg"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("invalid error for unmapped extension: %v", err)
+	}
+	result, err := Unmarshal("@./test/server.cfg", WithExtensionMap(map[string]Format{".CFG": FormatYAML}))
+	if err != nil {
+		t.Fatalf("error unmarshalling mapped extension: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"host": "localhost", "port": 8080}) {
+		t.Errorf("invalid result: %v", result)
+	}
+	// custom mappings override the built-in ones
+	_, err = Unmarshal("@./test/struct.json", WithExtensionMap(map[string]Format{"json": FormatTOML}))
+	if err == nil || !strings.Contains(err.Error(), "TOML") {
+		t.Errorf("invalid error for overridden extension: %v", err)
+	}
+	format, err := DetectFormat("@./test/struct.conf", WithExtensionMap(map[string]Format{".conf": FormatJSON}))
+	if err != nil || format != FormatJSON {
+		t.Errorf("invalid detected format: %v (error: %v)", format, err)
+	}
+}
+
+func TestWithRequireFile(t *testing.T) {
+	for _, input := range []string{
+		`{"name": "John"}`,
+		`json:{"name": "John"}`,
+		"---\nname: John\n",
+		`json:\@x`,
+		"yaml:@@x",
+		"@-",
+		"https://example.com/config.json",
+		"data:application/json,%7B%7D",
+	} {
+		if _, err := Unmarshal(input, WithRequireFile(true)); !errors.Is(err, ErrInlineNotAllowed) {
+			t.Errorf("expected ErrInlineNotAllowed for %q, got %v", input, err)
+		}
+	}
+	for _, input := range []string{"@./test/struct.json", "yaml:@./test/struct.yaml"} {
+		if _, err := Unmarshal(input, WithRequireFile(true)); err != nil {
+			t.Errorf("error unmarshalling %q with files required: %v", input, err)
+		}
+	}
+	RegisterSource("required", FormatJSON, []byte(`{"name": "John"}`))
+	defer RegisterSource("required", FormatJSON, nil)
+	if _, err := Unmarshal("@mem:required", WithRequireFile(true)); err != nil {
+		t.Errorf("error unmarshalling in-memory source with files required: %v", err)
+	}
+	if _, err := Unmarshal(`{"name": "John"}`, WithRequireFile(false)); err != nil {
+		t.Errorf("error unmarshalling inline value: %v", err)
+	}
+}
+
+func TestWithContentTransform(t *testing.T) {
+	encrypted := regexp.MustCompile(`ENC\[(\w+)\]`)
+	formats := []Format{}
+	decrypt := func(format Format, content []byte) ([]byte, error) {
+		formats = append(formats, format)
+		return encrypted.ReplaceAllFunc(content, func(match []byte) []byte {
+			value := encrypted.FindSubmatch(match)[1]
+			plain := make([]byte, len(value))
+			for i, c := range value {
+				plain[i] = 'a' + (c-'a'+13)%26
+			}
+			return plain
+		}), nil
+	}
+	result, err := Unmarshal("@./test/secrets.yaml", WithContentTransform(decrypt))
+	if err != nil {
+		t.Fatalf("error unmarshalling with transform: %v", err)
+	}
+	if password, _ := GetPath(result, "database.password"); password != "secret" {
+		t.Errorf("invalid decrypted value: %v", password)
+	}
+	if _, err := UnmarshalReader(strings.NewReader(`{"password": "ENC[frperg]"}`), FormatUnknown, WithContentTransform(decrypt)); err != nil {
+		t.Fatalf("error unmarshalling reader with transform: %v", err)
+	}
+	if !reflect.DeepEqual(formats, []Format{FormatYAML, FormatJSON}) {
+		t.Errorf("invalid formats passed to transform: %v", formats)
+	}
+	failure := errors.New("no key")
+	_, err = Unmarshal("@./test/secrets.yaml", WithContentTransform(func(Format, []byte) ([]byte, error) { return nil, failure }))
+	if !errors.Is(err, failure) {
+		t.Errorf("expected error from transform, got %v", err)
+	}
+}
+
+func TestWithDecodePath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "my config.json"), []byte(`{"name": "John"}`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	expected := map[string]interface{}{"name": "John"}
+	for _, input := range []string{
+		"@" + filepath.ToSlash(dir) + "/my%20config.json",
+		"@" + strings.ReplaceAll(filepath.ToSlash(dir), "/", "%2F") + "%2Fmy%20config.json",
+		"json:@" + filepath.ToSlash(dir) + "/my%20config%2Ejson",
+		"file://" + filepath.ToSlash(dir) + "/my%20config.json",
+	} {
+		result, err := Unmarshal(input, WithDecodePath(true))
+		if err != nil {
+			t.Errorf("error unmarshalling %q: %v", input, err)
+		} else if !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for %q: %v", input, result)
+		}
+	}
+	// paths are only decoded if asked to
+	if _, err := Unmarshal("@"+filepath.ToSlash(dir)+"/my%20config.json", WithDecodePath(false)); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error without decoding: %v", err)
+	}
+	if _, err := Unmarshal("@"+filepath.ToSlash(dir)+"/my%zzconfig.json", WithDecodePath(true)); err == nil || errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for bad encoding: %v", err)
+	}
+	// inline data is never decoded
+	for input, expected := range map[string]interface{}{
+		`{"name": "my%20name"}`:         map[string]interface{}{"name": "my%20name"},
+		`properties:\@user = my%20name`: map[string]interface{}{"@user": "my%20name"},
+	} {
+		result, err := Unmarshal(input, WithDecodePath(true))
+		if err != nil || !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for inline %q: %v (%v)", input, result, err)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// OrderedMap is a map that remembers the order in which its keys were
+// inserted; it is returned in place of map[string]interface{} when the
+// WithOrderedMaps option is set, so that objects keep the same key order
+// they have in the input.
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewOrderedMap returns a new, empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{
+		values: map[string]interface{}{},
+	}
+}
+
+// Get returns the value associated with the given key, and whether the key
+// is present in the map.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set associates the value with the given key; new keys are appended at the
+// end, whereas existing keys keep their position.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes the given key from the map, if present.
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in the map, in insertion order.
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
+
+// Len returns the number of keys in the map.
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Range calls fn for each key and value in the map, in insertion order; if
+// fn returns false, the iteration stops.
+func (m *OrderedMap) Range(fn func(key string, value interface{}) bool) {
+	for _, key := range m.keys {
+		if !fn(key, m.values[key]) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements json.Marshaler, writing the keys in order.
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(k)
+		buffer.WriteByte(':')
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(v)
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// MarshalYAML implements yaml.Marshaler, writing the keys in order.
+func (m *OrderedMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range m.keys {
+		value := &yaml.Node{}
+		if err := value.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	}
+	return node, nil
+}
+
+// unmarshalOrderedJSON unmarshals a JSON document, using OrderedMaps for
+// objects; it streams through the tokens in the document, so that the order
+// of the keys is known.
+func unmarshalOrderedJSON(content []byte, o *options) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if o.useNumber {
+		decoder.UseNumber()
+	}
+	result, err := decodeOrderedJSON(decoder)
+	if err != nil {
+		return nil, newDecodeError(FormatJSON, content, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, newDecodeError(FormatJSON, content, errTrailingData)
+	}
+	return result, nil
+}
+
+// decodeOrderedJSON decodes the next JSON value from the decoder.
+func decodeOrderedJSON(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delimiter, ok := token.(json.Delim)
+	if !ok {
+		// a scalar value
+		return token, nil
+	}
+	switch delimiter {
+	case '{':
+		object := NewOrderedMap()
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := token.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid object key %v", token)
+			}
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(key, value)
+		}
+		// consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case '[':
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrderedJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		// consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return array, nil
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %v", delimiter)
+	}
+}
+
+// decodeYAMLNode walks a YAML node tree and converts it into a generic
+// value, using OrderedMaps for mappings and json.Numbers for numbers if the
+// options say so; explicit keys in a mapping take precedence over keys
+// coming from merge keys ("<<"), regardless of their position.
+func decodeYAMLNode(node *yaml.Node, o *options) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return decodeYAMLNode(node.Alias, o)
+	case yaml.MappingNode:
+		object := NewOrderedMap()
+		explicit := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Tag != "!!merge" {
+				explicit[node.Content[i].Value] = true
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				if err := mergeYAMLNode(object, value, explicit, o); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			v, err := decodeYAMLNode(value, o)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(key.Value, v)
+		}
+		if !o.orderedMaps {
+			return object.values, nil
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := []interface{}{}
+		for _, item := range node.Content {
+			v, err := decodeYAMLNode(item, o)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+	default:
+		if o.useNumber {
+			if number, ok := yamlNumber(node); ok {
+				return number, nil
+			}
+		}
+		var scalar interface{}
+		if err := node.Decode(&scalar); err != nil {
+			return nil, err
+		}
+		return scalar, nil
+	}
+}
+
+// mergeYAMLNode merges the mapping (or sequence of mappings) referenced by
+// a merge key into the object, skipping keys that are explicitly set.
+func mergeYAMLNode(object *OrderedMap, node *yaml.Node, explicit map[string]bool, o *options) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	sources := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		sources = node.Content
+	}
+	for _, source := range sources {
+		if source.Kind == yaml.AliasNode {
+			source = source.Alias
+		}
+		if source.Kind != yaml.MappingNode {
+			return fmt.Errorf("invalid merge key value at line %d", source.Line)
+		}
+		// decode into an OrderedMap regardless of the options, so that the
+		// merged keys keep their order
+		ordered := *o
+		ordered.orderedMaps = true
+		merged, err := decodeYAMLNode(source, &ordered)
+		if err != nil {
+			return err
+		}
+		merged.(*OrderedMap).Range(func(key string, value interface{}) bool {
+			if _, ok := object.Get(key); !ok && !explicit[key] {
+				object.Set(key, value)
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// yamlNumber returns the number in a YAML scalar node as a json.Number, which
+// preserves its original representation; it returns false if the node is not
+// a number or if it cannot be represented as a JSON number (e.g. ".inf", or
+// hexadecimal integers).
+func yamlNumber(node *yaml.Node) (json.Number, bool) {
+	if tag := node.ShortTag(); tag != "!!int" && tag != "!!float" {
+		return "", false
+	}
+	number := json.Number(strings.TrimPrefix(node.Value, "+"))
+	if !isJSONNumber(string(number)) {
+		return "", false
+	}
+	return number, true
+}
+
+// isJSONNumber returns whether the string is a valid JSON number literal.
+func isJSONNumber(s string) bool {
+	var number json.Number
+	return json.Unmarshal([]byte(s), &number) == nil
+}
+
+// convertTOML converts the maps in a decoded TOML document into OrderedMaps,
+// sorting the keys by their position in the document as recorded in the
+// metadata, and the numbers into json.Numbers, if the options say so.
+func convertTOML(object map[string]interface{}, metadata toml.MetaData, o *options) interface{} {
+	order := map[string]int{}
+	for i, key := range metadata.Keys() {
+		order[strings.Join(key, "\x00")] = i
+	}
+	return convertTOMLValue(object, nil, order, o)
+}
+
+// convertTOMLValue recursively converts a decoded TOML value; path is the
+// sequence of keys leading to the value.
+func convertTOMLValue(v interface{}, path []string, order map[string]int, o *options) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		position := func(key string) int {
+			if i, ok := order[strings.Join(append(path, key), "\x00")]; ok {
+				return i
+			}
+			return len(order)
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			return position(keys[i]) < position(keys[j])
+		})
+		object := NewOrderedMap()
+		for _, key := range keys {
+			object.Set(key, convertTOMLValue(v[key], append(path[:len(path):len(path)], key), order, o))
+		}
+		if !o.orderedMaps {
+			return object.values
+		}
+		return object
+	case []map[string]interface{}:
+		array := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			array = append(array, convertTOMLValue(item, path, order, o))
+		}
+		return array
+	case []interface{}:
+		array := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			array = append(array, convertTOMLValue(item, path, order, o))
+		}
+		return array
+	case int64:
+		if o.useNumber {
+			return json.Number(strconv.FormatInt(v, 10))
+		}
+		return v
+	case float64:
+		if o.useNumber && isJSONNumber(strconv.FormatFloat(v, 'g', -1, 64)) {
+			return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,84 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := NewOrderedMap()
+	m.Set("z", 1)
+	m.Set("a", 2)
+	m.Set("m", 3)
+	m.Set("a", 4)
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"z", "a", "m"}) {
+		t.Fatalf("invalid keys: %v", keys)
+	}
+	if v, ok := m.Get("a"); !ok || v != 4 {
+		t.Fatalf("invalid value for key: %v", v)
+	}
+	m.Delete("a")
+	if _, ok := m.Get("a"); ok || m.Len() != 2 {
+		t.Fatalf("key not deleted: %v", m.Keys())
+	}
+	visited := []string{}
+	m.Range(func(key string, value interface{}) bool {
+		visited = append(visited, key)
+		return false
+	})
+	if !reflect.DeepEqual(visited, []string{"z"}) {
+		t.Fatalf("invalid iteration: %v", visited)
+	}
+	data, err := json.Marshal(m)
+	if err != nil || string(data) != `{"z":1,"m":3}` {
+		t.Fatalf("invalid JSON: %s (error: %v)", data, err)
+	}
+	data, err = yaml.Marshal(m)
+	if err != nil || string(data) != "z: 1\nm: 3\n" {
+		t.Fatalf("invalid YAML: %s (error: %v)", data, err)
+	}
+}
+
+func TestUnmarshalWithOrderedMaps(t *testing.T) {
+	for _, input := range []string{
+		`{"zulu": 1, "alpha": {"yankee": true, "bravo": null}, "mike": [{"x": 1, "c": 2}]}`,
+		"---\nzulu: 1\nalpha:\n  yankee: true\n  bravo: ~\nmike:\n  - x: 1\n    c: 2\n",
+		"toml:zulu = 1\nalpha = { yankee = true, bravo = 'null' }\n[[mike]]\nx = 1\nc = 2\n",
+	} {
+		result, err := Unmarshal(input, WithOrderedMaps(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		m, ok := result.(*OrderedMap)
+		if !ok {
+			t.Fatalf("invalid output type: %T", result)
+		}
+		if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"zulu", "alpha", "mike"}) {
+			t.Fatalf("invalid keys for %q: %v", input, keys)
+		}
+		alpha, _ := m.Get("alpha")
+		if keys := alpha.(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"yankee", "bravo"}) {
+			t.Fatalf("invalid nested keys for %q: %v", input, keys)
+		}
+		mike, _ := m.Get("mike")
+		if keys := mike.([]interface{})[0].(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"x", "c"}) {
+			t.Fatalf("invalid keys in array for %q: %v", input, keys)
+		}
+	}
+}
+
+func TestUnmarshalWithOrderedMapsYAMLMerge(t *testing.T) {
+	input := "---\nbase: &base\n  a: 1\n  b: 2\nderived:\n  <<: *base\n  b: 3\n  c: 4\n"
+	result, err := Unmarshal(input, WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	derived, _ := result.(*OrderedMap).Get("derived")
+	data, err := json.Marshal(derived)
+	if err != nil || string(data) != `{"a":1,"b":3,"c":4}` {
+		t.Fatalf("invalid merged mapping: %s (error: %v)", data, err)
+	}
+}
@@ -0,0 +1,72 @@
+package rawdata
+
+import (
+	"strconv"
+	"strings"
+)
+
+// GetPath returns the value at the given path in a value as returned by
+// Unmarshal, and whether it exists: the path is a sequence of object keys and
+// array indexes joined by a separator ("." unless set via WithSeparator, as
+// for Flatten), e.g. "servers.0.host"; an empty path refers to the value
+// itself. If WithCaseInsensitivePaths is set, keys that only differ in case
+// from a path segment match it too, as long as there is an exact match or a
+// single candidate.
+func GetPath(v interface{}, path string, opts ...Option) (interface{}, bool) {
+	o := newOptions(opts...)
+	if path == "" {
+		return v, true
+	}
+	for _, segment := range strings.Split(path, o.separator) {
+		var ok bool
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if v, ok = value[segment]; !ok && o.caseInsensitivePaths {
+				v, ok = lookupFold(segment, func(fn func(key string, item interface{}) bool) {
+					for key, item := range value {
+						if !fn(key, item) {
+							return
+						}
+					}
+				})
+			}
+		case *OrderedMap:
+			if value == nil {
+				return nil, false
+			}
+			if v, ok = value.Get(segment); !ok && o.caseInsensitivePaths {
+				v, ok = lookupFold(segment, value.Range)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			v, ok = value[index], true
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// lookupFold returns the value whose key matches the given one regardless of
+// case, if there is exactly one.
+func lookupFold(key string, iterate func(fn func(key string, item interface{}) bool)) (interface{}, bool) {
+	var (
+		result  interface{}
+		matches int
+	)
+	iterate(func(k string, item interface{}) bool {
+		if strings.EqualFold(k, key) {
+			result = item
+			matches++
+		}
+		return matches < 2
+	})
+	if matches != 1 {
+		return nil, false
+	}
+	return result, true
+}
@@ -0,0 +1,61 @@
+package rawdata
+
+import (
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+	value, err := Unmarshal(`{"Server": {"Hosts": [{"name": "a"}, {"name": "b"}], "port": 80, "none": null}, "dup": {"x": 1, "X": 2}}`)
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	ordered, err := Unmarshal(`{"Server": {"Hosts": [{"name": "a"}, {"name": "b"}], "port": 80, "none": null}, "dup": {"x": 1, "X": 2}}`, WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	testCases := []struct {
+		path     string
+		opts     []Option
+		expected interface{}
+		found    bool
+	}{
+		{path: "Server.port", expected: float64(80), found: true},
+		{path: "Server.Hosts.1.name", expected: "b", found: true},
+		{path: "Server/Hosts/0/name", opts: []Option{WithSeparator("/")}, expected: "a", found: true},
+		{path: "Server.none", expected: nil, found: true},
+		{path: "server.port"},
+		{path: "server.PORT", opts: []Option{WithCaseInsensitivePaths(true)}, expected: float64(80), found: true},
+		{path: "dup.x", opts: []Option{WithCaseInsensitivePaths(true)}, expected: float64(1), found: true},
+		{path: "DUP.X", opts: []Option{WithCaseInsensitivePaths(true)}, expected: float64(2), found: true},
+		{path: "Dup.x", opts: []Option{WithCaseInsensitivePaths(true)}, expected: float64(1), found: true},
+		{path: "Server.Hosts.2.name"},
+		{path: "Server.Hosts.-1"},
+		{path: "Server.Hosts.name"},
+		{path: "Server.port.value"},
+		{path: "Server.none.value"},
+	}
+	for _, v := range []interface{}{value, ordered} {
+		for _, test := range testCases {
+			result, found := GetPath(v, test.path, test.opts...)
+			if found != test.found || result != test.expected {
+				t.Errorf("invalid result for %q: expected %v (%t), got %v (%t)", test.path, test.expected, test.found, result, found)
+			}
+		}
+	}
+	if _, found := GetPath(map[string]interface{}{"x": 1, "X": 2}, "dUp", WithCaseInsensitivePaths(true)); found {
+		t.Error("expected ambiguous key not to be found")
+	}
+	if _, found := GetPath(map[string]interface{}{"ab": 1, "AB": 2}, "Ab", WithCaseInsensitivePaths(true)); found {
+		t.Error("expected ambiguous key not to be found")
+	}
+	if result, found := GetPath(value, ""); !found || !IsObject(result) {
+		t.Errorf("invalid result for empty path: %v", result)
+	}
+	var nilMap *OrderedMap
+	if _, found := GetPath(nilMap, "a"); found {
+		t.Error("expected nothing to be found in nil map")
+	}
+	if _, found := GetPath(nil, "a"); found {
+		t.Error("expected nothing to be found in nil value")
+	}
+}
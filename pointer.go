@@ -0,0 +1,56 @@
+package rawdata
+
+import (
+	"bytes"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeIntoPointer decodes the content into the value the given pointer
+// points to, setting the pointer to nil if the data is null, or allocating
+// the value if the pointer is nil and the data is not null.
+func decodeIntoPointer(format Format, content []byte, pointer reflect.Value, o *options) error {
+	if isNull(format, content, o) {
+		pointer.Set(reflect.Zero(pointer.Type()))
+		return nil
+	}
+	if !pointer.IsNil() {
+		return decodeInto(format, content, pointer.Interface(), o)
+	}
+	value := reflect.New(pointer.Type().Elem())
+	if err := decodeInto(format, content, value.Interface(), o); err != nil {
+		return err
+	}
+	pointer.Set(value)
+	return nil
+}
+
+// isNull returns whether the content is empty or represents a null value;
+// the formats that can only represent objects are never null unless empty.
+func isNull(format Format, content []byte, o *options) bool {
+	if isEmpty(content) {
+		return true
+	}
+	switch format {
+	case FormatJSON:
+		return bytes.Equal(bytes.TrimSpace(prepareJSON(content, o)), []byte("null"))
+	case FormatYAML:
+		document := yaml.Node{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			// let the decoder report the error
+			return false
+		}
+		if len(document.Content) == 0 {
+			// only comments
+			return true
+		}
+		root := document.Content[0]
+		if root.Kind == yaml.AliasNode {
+			root = root.Alias
+		}
+		return root.Kind == yaml.ScalarNode && root.ShortTag() == "!!null"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package rawdata
+
+import (
+	"testing"
+)
+
+type pointerConfig struct {
+	Name string            `json:"name" yaml:"name" toml:"name"`
+	Sub  *pointerSubConfig `json:"sub" yaml:"sub" toml:"sub"`
+}
+
+type pointerSubConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"enabled"`
+}
+
+func TestUnmarshalIntoPointerToPointer(t *testing.T) {
+	for input, expected := range map[string]bool{
+		`{"name": "a", "sub": {"enabled": false}}`: true,
+		`{"name": "a", "sub": null}`:               false,
+		`{"name": "a"}`:                            false,
+		"---\nname: a\nsub: {}\n":                  true,
+		"---\nname: a\nsub: ~\n":                   false,
+		"toml:name = 'a'\n[sub]\n":                 true,
+	} {
+		var config *pointerConfig
+		if err := UnmarshalInto(input, &config); err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if config == nil || config.Name != "a" {
+			t.Fatalf("invalid result for %q: %+v", input, config)
+		}
+		if (config.Sub != nil) != expected {
+			t.Errorf("invalid section for %q: %+v", input, config.Sub)
+		}
+	}
+	for _, input := range []string{"json:null", "--- ~", "---\n# nothing\n", "  "} {
+		config := &pointerConfig{Name: "previous"}
+		if err := UnmarshalInto(input, &config, WithAllowEmpty(true)); err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if config != nil {
+			t.Errorf("pointer not set to nil for %q: %+v", input, config)
+		}
+	}
+	// an existing value is decoded into, as with encoding/json
+	existing := &pointerConfig{Name: "previous", Sub: &pointerSubConfig{Enabled: true}}
+	config := existing
+	if err := UnmarshalInto(`{"name": "a"}`, &config); err != nil {
+		t.Fatalf("error unmarshalling into existing value: %v", err)
+	}
+	if config != existing || config.Name != "a" || config.Sub == nil {
+		t.Errorf("invalid result for existing value: %+v", config)
+	}
+	var number **int
+	if err := UnmarshalInto("42", &number, WithAllowScalars(true)); err != nil || number == nil || *number == nil || **number != 42 {
+		t.Errorf("invalid result for pointer to pointer: %v (error: %v)", number, err)
+	}
+}
@@ -0,0 +1,172 @@
+package rawdata
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// unmarshalProperties unmarshals Java properties (or INI) data into an
+// object, where dotted keys (e.g. "server.port") become nested objects and
+// all values are strings. Keys and values can be separated by '=' or ':',
+// lines starting with '#' or '!' (or ';', as in INI) are comments, and a
+// trailing backslash continues a line on the next one; INI section headers
+// (e.g. "[server]") prefix the keys that follow them with the section name.
+func unmarshalProperties(content []byte, o *options) (interface{}, error) {
+	result := newObject(o)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	section := ""
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' || line[0] == ';' {
+			continue
+		}
+		// join continuation lines
+		for hasContinuation(line) && scanner.Scan() {
+			number++
+			line = line[:len(line)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+		line = strings.TrimSuffix(line, "\\")
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(strings.TrimSpace(line), "]") {
+			section = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line)[1:], "]"))
+			continue
+		}
+		key, value := splitProperty(line)
+		key, err := unescapeProperty(strings.TrimSpace(key))
+		if err != nil {
+			return nil, &DecodeError{DataFormat: FormatProperties, Line: number, Err: err}
+		}
+		value, err = unescapeProperty(strings.TrimLeft(value, " \t\f"))
+		if err != nil {
+			return nil, &DecodeError{DataFormat: FormatProperties, Line: number, Err: err}
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if err := setProperty(result, strings.Split(key, "."), value, o); err != nil {
+			return nil, &DecodeError{DataFormat: FormatProperties, Line: number, Err: err}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, newDecodeError(FormatProperties, content, err)
+	}
+	return result, nil
+}
+
+// hasContinuation returns whether the line ends with an odd number of
+// backslashes, i.e. with an unescaped backslash.
+func hasContinuation(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// splitProperty splits a line at the first unescaped '=' or ':'; if there is
+// none, the whole line is the key and the value is empty.
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], line[i+1:]
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty replaces the escape sequences in a key or value (e.g. "\t"
+// or "\u00e9") with the characters they represent; any other escaped
+// character stands for itself.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			builder.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape in '%s'", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in '%s'", s)
+			}
+			builder.WriteRune(rune(r))
+			i += 4
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+// setProperty sets the value at the given path in the object, creating any
+// intermediate objects as needed.
+func setProperty(object interface{}, path []string, value string, o *options) error {
+	for i, key := range path[:len(path)-1] {
+		child, ok := getKey(object, key)
+		if !ok {
+			child = newObject(o)
+			setKey(object, key, child)
+		} else if _, isString := child.(string); isString {
+			return fmt.Errorf("cannot set '%s': '%s' already has a value", strings.Join(path, "."), strings.Join(path[:i+1], "."))
+		}
+		object = child
+	}
+	key := path[len(path)-1]
+	if existing, ok := getKey(object, key); ok {
+		if _, isString := existing.(string); !isString {
+			return fmt.Errorf("cannot set '%s': it already has nested keys", strings.Join(path, "."))
+		}
+	}
+	setKey(object, key, value)
+	return nil
+}
+
+// newObject returns a new, empty object: an OrderedMap if ordered maps are
+// enabled, a map[string]interface{} otherwise.
+func newObject(o *options) interface{} {
+	if o.orderedMaps {
+		return NewOrderedMap()
+	}
+	return map[string]interface{}{}
+}
+
+// getKey returns the value of the key in an object created by newObject.
+func getKey(object interface{}, key string) (interface{}, bool) {
+	if m, ok := object.(*OrderedMap); ok {
+		return m.Get(key)
+	}
+	value, ok := object.(map[string]interface{})[key]
+	return value, ok
+}
+
+// setKey sets the value of the key in an object created by newObject.
+func setKey(object interface{}, key string, value interface{}) {
+	if m, ok := object.(*OrderedMap); ok {
+		m.Set(key, value)
+		return
+	}
+	object.(map[string]interface{})[key] = value
+}
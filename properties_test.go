@@ -0,0 +1,57 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalProperties(t *testing.T) {
+	result, err := Unmarshal("@./test/app.properties")
+	if err != nil {
+		t.Fatalf("error unmarshalling properties: %v", err)
+	}
+	expected := map[string]interface{}{
+		"app": map[string]interface{}{"name": "My Application"},
+		"server": map[string]interface{}{
+			"port": "8080",
+			"host": "localhost",
+		},
+		"message":  "hello world",
+		"path":     `C:\temp`,
+		"greeting": "café",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+
+	result, err = Unmarshal("properties:b=1\na.y=2\na.x=3", WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling ordered properties: %v", err)
+	}
+	object := result.(*OrderedMap)
+	nested, _ := object.Get("a")
+	if !reflect.DeepEqual(object.Keys(), []string{"b", "a"}) || !reflect.DeepEqual(nested.(*OrderedMap).Keys(), []string{"y", "x"}) {
+		t.Errorf("invalid key order: %v", result)
+	}
+
+	for _, input := range []string{"properties:a=1\na.b=2", "properties:a.b=1\na=2", `properties:a=\u12`} {
+		if _, err := Unmarshal(input); err == nil {
+			t.Errorf("no error unmarshalling %q", input)
+		}
+	}
+}
+
+func TestUnmarshalINI(t *testing.T) {
+	target := struct {
+		Database struct {
+			User     string `json:"user"`
+			Password string `json:"password"`
+		} `json:"database"`
+	}{}
+	if err := UnmarshalInto("@./test/app.ini", &target); err != nil {
+		t.Fatalf("error unmarshalling INI: %v", err)
+	}
+	if target.Database.User != "admin" || target.Database.Password != "secret" {
+		t.Errorf("invalid target: %+v", target)
+	}
+}
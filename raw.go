@@ -0,0 +1,120 @@
+package rawdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Raw holds a portion of the data undecoded, along with its format, so that
+// it can be decoded later, e.g. into a type that depends on other values in
+// the data (as in two-phase decoding of polymorphic configurations). When a
+// struct field (or any other value) of type Raw is the target of UnmarshalInto,
+// it receives the encoded data of the corresponding value: as is for JSON, or
+// re-encoded on its own for YAML (with aliases resolved) and TOML (as JSON,
+// since TOML cannot encode values other than tables on their own).
+type Raw struct {
+	// Format is the format of the data.
+	Format Format
+	// Data is the encoded data.
+	Data []byte
+}
+
+// String returns the data with a prefix specifying its format (e.g.
+// "json:{...}"), for display; the data is decoded as it is by Unmarshal and
+// UnmarshalInto, never taken as a reference to a file, a URL or a data URI.
+func (r Raw) String() string {
+	data := string(r.Data)
+	if strings.HasPrefix(data, "@") {
+		// never a file reference
+		data = "@" + data
+	}
+	if r.Format == FormatUnknown {
+		return data
+	}
+	return r.Format.String() + ":" + data
+}
+
+// UnmarshalInto decodes the data into the given target, as with the
+// package-level UnmarshalInto.
+func (r Raw) UnmarshalInto(target interface{}, opts ...Option) error {
+	o := newOptions(opts...)
+	format, err := r.format(o)
+	if err != nil {
+		return err
+	}
+	return decodeContentInto(format, r.Data, target, "raw value", o)
+}
+
+// Unmarshal decodes the data into a generic value, as with the package-level
+// Unmarshal.
+func (r Raw) Unmarshal(opts ...Option) (interface{}, error) {
+	o := newOptions(opts...)
+	format, err := r.format(o)
+	if err != nil {
+		return nil, err
+	}
+	return decodeContent(format, r.Data, "", "raw value", o)
+}
+
+// format returns the format of the data, detecting it from the data itself
+// if it is not known.
+func (r Raw) format(o *options) (Format, error) {
+	if r.Format != FormatUnknown {
+		return r.Format, nil
+	}
+	if isEmpty(r.Data) {
+		if o.allowEmpty {
+			return FormatUnknown, nil
+		}
+		return FormatUnknown, fmt.Errorf("no data in raw value: %w", ErrEmptyContent)
+	}
+	return sniffFormat(r.Data, o)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (r *Raw) UnmarshalJSON(data []byte) error {
+	r.Format = FormatJSON
+	r.Data = append([]byte(nil), data...)
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *Raw) UnmarshalYAML(node *yaml.Node) error {
+	// aliases may refer to anchors outside of the node
+	data, err := yaml.Marshal(resolveYAMLAliases(node))
+	if err != nil {
+		return fmt.Errorf("error encoding raw YAML data: %w", err)
+	}
+	r.Format = FormatYAML
+	r.Data = data
+	return nil
+}
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (r *Raw) UnmarshalTOML(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error encoding raw TOML data: %w", err)
+	}
+	r.Format = FormatJSON
+	r.Data = data
+	return nil
+}
+
+// resolveYAMLAliases returns a copy of the node tree where aliases are
+// replaced with copies of the nodes they refer to, and anchors are dropped.
+func resolveYAMLAliases(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return resolveYAMLAliases(node.Alias)
+	}
+	resolved := *node
+	resolved.Anchor = ""
+	resolved.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		resolved.Content[i] = resolveYAMLAliases(child)
+	}
+	return &resolved
+}
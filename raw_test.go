@@ -0,0 +1,85 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type rawShape struct {
+	Kind string `json:"kind" yaml:"kind" toml:"kind"`
+	Spec Raw    `json:"spec" yaml:"spec" toml:"spec"`
+}
+
+type rawCircle struct {
+	Radius float64 `json:"radius" yaml:"radius"`
+}
+
+type rawRectangle struct {
+	Width  float64 `json:"width" yaml:"width"`
+	Height float64 `json:"height" yaml:"height"`
+}
+
+func TestRaw(t *testing.T) {
+	for _, input := range []string{
+		`{"shapes": [{"kind": "circle", "spec": {"radius": 2}}, {"kind": "rectangle", "spec": {"width": 3, "height": 4}}]}`,
+		"---\nshapes:\n- kind: circle\n  spec:\n    radius: 2\n- kind: rectangle\n  spec:\n    width: 3\n    height: 4\n",
+		"---\nbase: &base {width: 3}\nshapes:\n- kind: circle\n  spec: {radius: 2}\n- kind: rectangle\n  spec:\n    <<: *base\n    height: 4\n",
+		"toml:[[shapes]]\nkind = 'circle'\n[shapes.spec]\nradius = 2\n[[shapes]]\nkind = 'rectangle'\n[shapes.spec]\nwidth = 3\nheight = 4\n",
+	} {
+		target := struct {
+			Shapes []rawShape `json:"shapes" yaml:"shapes" toml:"shapes"`
+		}{}
+		if err := UnmarshalInto(input, &target); err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		shapes := target.Shapes
+		if len(shapes) != 2 {
+			t.Fatalf("invalid shapes for %q: %v", input, shapes)
+		}
+		circle := rawCircle{}
+		if err := shapes[0].Spec.UnmarshalInto(&circle); err != nil {
+			t.Fatalf("error unmarshalling circle from %q (%s): %v", input, shapes[0].Spec, err)
+		}
+		rectangle := rawRectangle{}
+		if err := shapes[1].Spec.UnmarshalInto(&rectangle, WithStrict(true)); err != nil {
+			t.Fatalf("error unmarshalling rectangle from %q (%s): %v", input, shapes[1].Spec, err)
+		}
+		if circle.Radius != 2 || rectangle.Width != 3 || rectangle.Height != 4 {
+			t.Errorf("invalid shapes for %q: %+v, %+v", input, circle, rectangle)
+		}
+	}
+}
+
+func TestRawString(t *testing.T) {
+	raw := Raw{Format: FormatJSON, Data: []byte(`{"a": 1}`)}
+	if raw.String() != `json:{"a": 1}` {
+		t.Errorf("invalid string: %q", raw.String())
+	}
+	result, err := raw.Unmarshal()
+	if err != nil || !reflect.DeepEqual(result, map[string]interface{}{"a": 1.0}) {
+		t.Errorf("invalid result: %v (error: %v)", result, err)
+	}
+	if _, err := (Raw{Format: FormatYAML, Data: []byte("@file.yaml")}).Unmarshal(); err == nil || errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for raw data starting with @: %v", err)
+	}
+}
+
+func TestRawSourceLikeData(t *testing.T) {
+	for _, data := range []string{"https://example.com/x", "http://127.0.0.1:1/x", "file:///etc/passwd", "data:application/json,%7B%7D"} {
+		for _, raw := range []Raw{
+			{Format: FormatYAML, Data: []byte(data + "\n")},
+			{Format: FormatYAML, Data: []byte(data)},
+			{Format: FormatJSON, Data: []byte(`"` + data + `"`)},
+		} {
+			result, err := raw.Unmarshal(WithAllowScalars(true))
+			if err != nil || result != data {
+				t.Errorf("invalid result for %q: %v (error: %v)", raw.Data, result, err)
+			}
+			var s string
+			if err := raw.UnmarshalInto(&s); err != nil || s != data {
+				t.Errorf("invalid string for %q: %q (error: %v)", raw.Data, s, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package rawdata
+
+import (
+	"fmt"
+	"io"
+)
+
+// UnmarshalReader is like Unmarshal, but it reads the data from the given
+// reader instead of from a value, and it decodes it according to the given
+// format; if the format is FormatUnknown, the whole stream is buffered and
+// the format is detected from the data, just like for inline values. Includes
+// (see WithIncludes) are resolved relative to the current directory, or to
+// the base directory if set.
+func UnmarshalReader(r io.Reader, format Format, opts ...Option) (interface{}, error) {
+	o := newOptions(opts...)
+	format, content, err := readStream(r, format, o)
+	if err != nil {
+		return nil, err
+	}
+	return decodeContent(format, content, "", "data in reader", o)
+}
+
+// UnmarshalReaderInto is like UnmarshalInto, but it reads the data from the
+// given reader instead of from a value, and it decodes it according to the
+// given format; if the format is FormatUnknown, the whole stream is buffered
+// and the format is detected from the data, just like for inline values.
+func UnmarshalReaderInto(r io.Reader, format Format, target interface{}, opts ...Option) error {
+	o := newOptions(opts...)
+	format, content, err := readStream(r, format, o)
+	if err != nil {
+		return err
+	}
+	return decodeContentInto(format, content, target, "data in reader", o)
+}
+
+// readStream reads all the data from the reader and detects its format if
+// it is not known in advance.
+func readStream(r io.Reader, format Format, o *options) (Format, []byte, error) {
+	content, err := readAll(r, o.maxFileSize)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error reading data: %w", err)
+	}
+	if content, err = decodeText(content); err != nil {
+		return FormatUnknown, nil, err
+	}
+	if isEmpty(content) {
+		if !o.allowEmpty {
+			return FormatUnknown, nil, fmt.Errorf("no data in reader: %w", ErrEmptyContent)
+		}
+	} else if format == FormatUnknown {
+		if format, err = sniffFormat(content, o); err != nil {
+			return FormatUnknown, nil, err
+		}
+	}
+	if err = checkEncoding(format, content); err != nil {
+		return FormatUnknown, nil, fmt.Errorf("invalid data in reader: %w", err)
+	}
+	if o.contentTransform != nil {
+		if content, err = o.contentTransform(format, content); err != nil {
+			return FormatUnknown, nil, fmt.Errorf("error transforming data in reader: %w", err)
+		}
+	}
+	if o.expandEnv {
+		content = expandEnv(content)
+	}
+	return format, content, nil
+}
@@ -0,0 +1,88 @@
+package rawdata
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalReader(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		format Format
+	}{
+		{"name: John\nsurname: Doe\nage: 23\n", FormatYAML},
+		{"name = 'John'\nsurname = 'Doe'\nage = 23\n", FormatTOML},
+		{`{"name": "John", "surname": "Doe", "age": 23}`, FormatUnknown},
+		{"---\nname: John\nsurname: Doe\nage: 23\n", FormatUnknown},
+	} {
+		result, err := UnmarshalReader(strings.NewReader(test.input), test.format)
+		if err != nil {
+			t.Fatalf("error unmarshalling from reader: %v", err)
+		}
+		if result, ok := result.(map[string]interface{}); !ok || result["name"] != "John" {
+			t.Fatalf("invalid output: %v (type %T)", result, result)
+		}
+
+		target := &s{}
+		if err := UnmarshalReaderInto(strings.NewReader(test.input), test.format, target); err != nil {
+			t.Fatalf("error unmarshalling from reader: %v", err)
+		}
+		if target.Name != "John" || target.Surname != "Doe" || target.Age != 23 {
+			t.Fatalf("invalid value unmarshalled from reader: %+v", target)
+		}
+	}
+
+	if _, err := UnmarshalReader(strings.NewReader("name: John"), FormatUnknown); !errors.Is(err, ErrUnrecognisedInline) {
+		t.Fatalf("invalid error on undetectable format: %v", err)
+	}
+}
+
+func TestUnmarshalReaderOptions(t *testing.T) {
+	schema, err := os.ReadFile("./test/person.schema.json")
+	if err != nil {
+		t.Fatalf("error reading schema: %v", err)
+	}
+	var schemaError *SchemaError
+
+	// generic values
+	result, err := UnmarshalReader(strings.NewReader(`{"tls": "@./test/include/shared/tls.yaml"}`), FormatJSON, WithIncludes(true))
+	if object, _ := AsObject(result); err != nil || !IsObject(object["tls"]) {
+		t.Errorf("includes not resolved: %v (%v)", result, err)
+	}
+	if _, err := UnmarshalReader(strings.NewReader(`{"name": "John"}`), FormatJSON, WithSchema(schema)); !errors.As(err, &schemaError) {
+		t.Errorf("schema not validated: %v", err)
+	}
+	result, err = UnmarshalReader(strings.NewReader(`{"at": "2024-01-02T03:04:05Z"}`), FormatJSON, WithParseTimestamps(true))
+	if object, _ := AsObject(result); err != nil || reflect.TypeOf(object["at"]) != reflect.TypeOf(time.Time{}) {
+		t.Errorf("timestamps not parsed: %v (%v)", result, err)
+	}
+	result, err = UnmarshalReader(strings.NewReader("# comment\nname: John\n"), FormatYAML, WithPreserveComments(true))
+	if _, ok := result.(*Document); err != nil || !ok {
+		t.Errorf("comments not preserved: %T (%v)", result, err)
+	}
+
+	// typed values
+	type config struct {
+		Name    string        `json:"name"`
+		Port    int           `json:"port"`
+		Timeout time.Duration `json:"timeout"`
+	}
+	c := config{}
+	if err := UnmarshalReaderInto(strings.NewReader(`{"name": "app"}`), FormatJSON, &c, WithDefaults(map[string]interface{}{"port": 8080})); err != nil || c.Port != 8080 {
+		t.Errorf("defaults not applied: %+v (%v)", c, err)
+	}
+	if err := UnmarshalReaderInto(strings.NewReader(`{"name": "John"}`), FormatJSON, &c, WithSchema(schema)); !errors.As(err, &schemaError) {
+		t.Errorf("schema not validated: %v", err)
+	}
+	var unknown []string
+	if err := UnmarshalReaderInto(strings.NewReader(`{"name": "app", "host": "x"}`), FormatJSON, &c, WithUnknownKeyHandler(func(path string) { unknown = append(unknown, path) })); err != nil || !reflect.DeepEqual(unknown, []string{"host"}) {
+		t.Errorf("unknown keys not reported: %v (%v)", unknown, err)
+	}
+	if err := UnmarshalReaderInto(strings.NewReader(`{"timeout": "5s"}`), FormatJSON, &c, WithDecodeHook(stringToDuration)); err != nil || c.Timeout != 5*time.Second {
+		t.Errorf("decode hooks not run: %+v (%v)", c, err)
+	}
+}
@@ -0,0 +1,140 @@
+package rawdata
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DecodeFunc decodes the given data into a generic value, i.e. a map, an
+// array or a scalar, as returned by Unmarshal.
+type DecodeFunc func(content []byte) (interface{}, error)
+
+// DecodeIntoFunc decodes the given data into the target, which is a pointer
+// as passed to UnmarshalInto.
+type DecodeIntoFunc func(content []byte, target interface{}) error
+
+// codec holds the functions used to decode a registered format.
+type codec struct {
+	decode     DecodeFunc
+	decodeInto DecodeIntoFunc
+}
+
+var (
+	// registryLock protects the registry, so that formats can be registered
+	// and used from multiple goroutines.
+	registryLock sync.RWMutex
+	// codecs maps registered formats to their decoding functions.
+	codecs = map[Format]codec{}
+	// extensions maps file extensions (lower case, with the leading dot) to
+	// registered formats.
+	extensions = map[string]Format{}
+)
+
+// RegisterFormat registers a custom format (e.g. CBOR or HCL), decoded by the
+// given function and associated with the given file extensions (e.g. ".hcl");
+// once registered, the format is supported by Unmarshal, UnmarshalInto and
+// all the other functions in the package. The extensions of registered
+// formats take precedence over the built-in ones, but not over those passed
+// via WithExtensionMap. Registering a format again replaces it.
+// RegisterFormat panics if the format is one of the built-in ones or if the
+// decoding function is nil.
+func RegisterFormat(format Format, ext []string, decode DecodeFunc) {
+	if isBuiltinFormat(format) {
+		panic(fmt.Sprintf("rawdata: RegisterFormat: cannot register built-in format %v", format))
+	}
+	if decode == nil {
+		panic("rawdata: RegisterFormat: decode function is nil")
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	for e, f := range extensions {
+		if f == format {
+			delete(extensions, e)
+		}
+	}
+	for _, e := range ext {
+		e = strings.ToLower(e)
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		extensions[e] = format
+	}
+	codecs[format] = codec{decode: decode}
+}
+
+// DecodeInto registers the function used to decode a custom format (see
+// RegisterFormat) into typed targets, as with UnmarshalInto; if none is
+// registered, the data is decoded into a generic value which is then
+// converted to the target type via its JSON representation. DecodeInto
+// panics if the format has not been registered.
+func DecodeInto(format Format, decodeInto DecodeIntoFunc) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	c, ok := codecs[format]
+	if !ok {
+		panic(fmt.Sprintf("rawdata: DecodeInto: format %v is not registered", format))
+	}
+	c.decodeInto = decodeInto
+	codecs[format] = c
+}
+
+// isBuiltinFormat returns whether the format is handled by the package
+// itself, including FormatUnknown.
+func isBuiltinFormat(format Format) bool {
+	switch format {
+	case FormatUnknown, FormatJSON, FormatYAML, FormatTOML, FormatCSV, FormatProperties, FormatXML, FormatNDJSON:
+		return true
+	}
+	return false
+}
+
+// registeredFormat returns the registered format associated with the given
+// file extension (lower case, with the leading dot), if any.
+func registeredFormat(ext string) (Format, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	format, ok := extensions[ext]
+	return format, ok
+}
+
+// registeredCodec returns the decoding functions of a registered format.
+func registeredCodec(format Format) (codec, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	c, ok := codecs[format]
+	return c, ok
+}
+
+// decodeRegistered decodes the content of a registered format.
+func decodeRegistered(format Format, content []byte) (interface{}, error) {
+	c, ok := registeredCodec(format)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, format)
+	}
+	result, err := c.decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling from %v: %w", format, err)
+	}
+	return result, nil
+}
+
+// decodeRegisteredInto decodes the content of a registered format into the
+// target.
+func decodeRegisteredInto(format Format, content []byte, target interface{}) error {
+	c, ok := registeredCodec(format)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrUnsupportedFormat, format)
+	}
+	if c.decodeInto != nil {
+		if err := c.decodeInto(content, target); err != nil {
+			return fmt.Errorf("error unmarshalling from %v: %w", format, err)
+		}
+		return nil
+	}
+	result, err := c.decode(content)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling from %v: %w", format, err)
+	}
+	return convertInto(result, target, format)
+}
@@ -0,0 +1,84 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// formatKeyValue is a custom format made of "key=value" lines.
+const formatKeyValue Format = 200
+
+func decodeKeyValue(content []byte) (interface{}, error) {
+	result := map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, errors.New("missing '=' in line")
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat(formatKeyValue, []string{"KV"}, decodeKeyValue)
+
+	result, err := Unmarshal("@./test/struct.kv")
+	if err != nil {
+		t.Fatalf("error unmarshalling registered format: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"name": "John", "surname": "Doe"}) {
+		t.Errorf("invalid result: %v", result)
+	}
+
+	// without a DecodeInto function, the value is converted via JSON
+	target := &s{}
+	if err := UnmarshalInto("@./test/struct.kv", target); err != nil {
+		t.Fatalf("error unmarshalling registered format into struct: %v", err)
+	}
+	if target.Name != "John" || target.Surname != "Doe" {
+		t.Errorf("invalid target: %+v", target)
+	}
+
+	called := false
+	DecodeInto(formatKeyValue, func(content []byte, target interface{}) error {
+		called = true
+		target.(*s).Name = "Jane"
+		return nil
+	})
+	if err := UnmarshalInto("@./test/struct.kv", target); err != nil || !called || target.Name != "Jane" {
+		t.Errorf("invalid DecodeInto result: %+v (error: %v)", target, err)
+	}
+
+	if _, err := Unmarshal("@./test/invalid.kv"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for missing file: %v", err)
+	}
+}
+
+func TestRegisterFormatConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterFormat(formatKeyValue, []string{".kv"}, decodeKeyValue)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = Unmarshal("@./test/struct.kv")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRegisterBuiltinFormat(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic registering a built-in format")
+		}
+	}()
+	RegisterFormat(FormatJSON, []string{".json"}, decodeKeyValue)
+}
@@ -0,0 +1,71 @@
+package rawdata
+
+import (
+	"strings"
+)
+
+// Result is what UnmarshalResult returns: the decoded value, along with the
+// format of the data and where it came from.
+type Result struct {
+	// Value is the decoded value, as returned by Unmarshal.
+	Value interface{}
+	// Format is the format of the data, as returned by UnmarshalWithFormat.
+	Format Format
+	// Source is where the data came from: the path of the file as given,
+	// without the "@" (e.g. "conf/app.yaml" for "@conf/app.yaml", or
+	// "/etc/app.yaml" for "file:///etc/app.yaml"), "-" for standard input,
+	// the URI for remote data and URIs with registered schemes, or "inline"
+	// for inline data, data URIs included.
+	Source string
+}
+
+// UnmarshalResult is like Unmarshal, but it returns the decoded value in a
+// Result, along with its format and its source, so that callers need not
+// keep track of them separately; Unmarshal is simpler when only the value
+// is needed.
+func UnmarshalResult(value string, opts ...Option) (*Result, error) {
+	result, format, err := UnmarshalWithFormat(value, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Value: result, Format: format, Source: sourceOf(value)}, nil
+}
+
+// Object returns the value as a map, and whether it is an object at all (see
+// AsObject).
+func (r *Result) Object() (map[string]interface{}, bool) {
+	return AsObject(r.Value)
+}
+
+// Array returns the value as a slice, and whether it is an array at all (see
+// AsArray).
+func (r *Result) Array() ([]interface{}, bool) {
+	return AsArray(r.Value)
+}
+
+// sourceOf returns where the data for the given value comes from, as
+// reported in Results.
+func sourceOf(value string) string {
+	_, value = cutFormatPrefix(value)
+	if _, ok := cutEscape(value); ok {
+		return "inline"
+	}
+	if isFileURI(value) {
+		if filename, err := fileURIPath(value); err == nil {
+			return filename
+		}
+	}
+	switch {
+	case strings.HasPrefix(value, "@"):
+		return strings.TrimPrefix(value, "@")
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return value
+	case isDataURI(value):
+		return "inline"
+	default:
+		if _, ok := registeredScheme(value); ok {
+			return value
+		}
+		return "inline"
+	}
+}
@@ -0,0 +1,51 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalResult(t *testing.T) {
+	tests := []struct {
+		input  string
+		format Format
+		source string
+		object bool
+	}{
+		{`{"name": "John"}`, FormatJSON, "inline", true},
+		{"---\n- a\n- b\n", FormatYAML, "inline", false},
+		{`properties:\@user = alice`, FormatProperties, "inline", true},
+		{"data:application/json,%5B1%5D", FormatJSON, "inline", false},
+		{"@./test/struct.yaml", FormatYAML, "./test/struct.yaml", true},
+		{"toml:@./test/struct.toml", FormatTOML, "./test/struct.toml", true},
+		{"@./test/array.json", FormatJSON, "./test/array.json", false},
+	}
+	for _, test := range tests {
+		result, err := UnmarshalResult(test.input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", test.input, err)
+		}
+		if result.Format != test.format || result.Source != test.source {
+			t.Errorf("invalid result for %q: expected %v from %q, got %v from %q", test.input, test.format, test.source, result.Format, result.Source)
+		}
+		object, isObject := result.Object()
+		array, isArray := result.Array()
+		if isObject != test.object || isArray == test.object {
+			t.Errorf("invalid shape for %q: object %v, array %v", test.input, isObject, isArray)
+		}
+		if test.object && !reflect.DeepEqual(object, result.Value) || !test.object && !reflect.DeepEqual(array, result.Value) {
+			t.Errorf("invalid value for %q: %v", test.input, result.Value)
+		}
+	}
+
+	result, err := UnmarshalResult(`{"b": 1, "a": 2}`, WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling ordered map: %v", err)
+	}
+	if object, ok := result.Object(); !ok || !reflect.DeepEqual(object, map[string]interface{}{"a": float64(2), "b": float64(1)}) {
+		t.Errorf("invalid object from ordered map: %v", object)
+	}
+	if result, err := UnmarshalResult("@./test/nonexisting.json"); err == nil || result != nil {
+		t.Errorf("expected error, got %v", result)
+	}
+}
@@ -0,0 +1,107 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// SchemaViolation describes a single way in which the data does not match a
+// JSON Schema.
+type SchemaViolation struct {
+	// Path is the JSON Pointer to the offending value in the data (e.g.
+	// "/server/port"), or "" for the data as a whole.
+	Path string
+	// Message describes the problem.
+	Message string
+}
+
+// SchemaError is returned when the data does not match a JSON Schema (see
+// ValidateAgainstSchema and WithSchema); it lists all the violations.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error implements the error interface.
+func (e *SchemaError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		path := violation.Path
+		if path == "" {
+			path = "/"
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", path, violation.Message))
+	}
+	return fmt.Sprintf("data does not match schema: %s", strings.Join(messages, "; "))
+}
+
+// ValidateAgainstSchema validates the given value, as returned by Unmarshal
+// (or any other value that can be represented as JSON), against the given JSON
+// Schema; the draft is taken from the "$schema" keyword, and defaults to
+// draft-07. If the value does not match the schema, the returned error is a
+// *SchemaError listing all the violations.
+func ValidateAgainstSchema(v interface{}, schema []byte) error {
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return err
+	}
+	return validateSchema(v, compiled)
+}
+
+// compileSchema compiles the given JSON Schema.
+func compileSchema(schema []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft7
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return compiled, nil
+}
+
+// validateSchema validates the given value against the compiled schema.
+func validateSchema(v interface{}, schema *jsonschema.Schema) error {
+	// the validator only understands the types produced by encoding/json, so
+	// other types (ordered maps, TOML dates, and so on) are converted first
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error converting data for validation: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("error converting data for validation: %w", err)
+	}
+	err = schema.Validate(value)
+	var validationError *jsonschema.ValidationError
+	if !errors.As(err, &validationError) {
+		return err
+	}
+	result := &SchemaError{}
+	collectViolations(validationError, result)
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Path < result.Violations[j].Path
+	})
+	return result
+}
+
+// collectViolations adds the innermost causes of a validation error to the
+// schema error.
+func collectViolations(err *jsonschema.ValidationError, result *SchemaError) {
+	if len(err.Causes) == 0 {
+		result.Violations = append(result.Violations, SchemaViolation{Path: err.InstanceLocation, Message: err.Message})
+		return
+	}
+	for _, cause := range err.Causes {
+		collectViolations(cause, result)
+	}
+}
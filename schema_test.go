@@ -0,0 +1,56 @@
+package rawdata
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema, err := os.ReadFile("test/person.schema.json")
+	if err != nil {
+		t.Fatalf("error reading schema: %v", err)
+	}
+	for _, value := range []string{"@./test/struct.json", "@./test/struct.yaml", "@./test/struct.toml"} {
+		result, err := Unmarshal(value, WithOrderedMaps(true))
+		if err != nil {
+			t.Fatalf("error unmarshalling %s: %v", value, err)
+		}
+		if err := ValidateAgainstSchema(result, schema); err != nil {
+			t.Errorf("invalid validation of %s: %v", value, err)
+		}
+	}
+
+	err = ValidateAgainstSchema(map[string]interface{}{"name": "", "age": -1.5, "nickname": "Johnny"}, schema)
+	var schemaError *SchemaError
+	if !errors.As(err, &schemaError) {
+		t.Fatalf("invalid error: %v (type %T)", err, err)
+	}
+	paths := []string{}
+	for _, violation := range schemaError.Violations {
+		paths = append(paths, violation.Path)
+	}
+	if len(schemaError.Violations) != 4 || !reflect.DeepEqual(paths, []string{"", "", "/age", "/name"}) {
+		t.Errorf("invalid violations: %v", err)
+	}
+
+	if err := ValidateAgainstSchema(nil, []byte(`{"type": 42}`)); err == nil || errors.As(err, &schemaError) {
+		t.Errorf("invalid error for invalid schema: %v", err)
+	}
+}
+
+func TestWithSchema(t *testing.T) {
+	schema := []byte(`{"type": "object", "required": ["name", "email"]}`)
+	var schemaError *SchemaError
+	if _, err := Unmarshal("@./test/struct.yaml", WithSchema(schema)); !errors.As(err, &schemaError) || len(schemaError.Violations) != 1 {
+		t.Errorf("invalid error unmarshalling: %v", err)
+	}
+	target := &s{}
+	if err := UnmarshalInto("@./test/struct.yaml", target, WithSchema(schema)); !errors.As(err, &schemaError) || target.Name != "" {
+		t.Errorf("invalid error unmarshalling into struct: %v (target: %+v)", err, target)
+	}
+	if err := UnmarshalInto(`{"name": "John", "email": "john@example.com"}`, target, WithSchema(schema)); err != nil || target.Name != "John" {
+		t.Errorf("invalid result unmarshalling valid data: %+v (error: %v)", target, err)
+	}
+}
@@ -0,0 +1,95 @@
+package rawdata
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// SchemeLoader loads the data referred to by a URI with a registered scheme
+// (see RegisterScheme); it returns the data and its format, or FormatUnknown
+// if the format should be detected from the data.
+type SchemeLoader func(ctx context.Context, uri string) (Format, []byte, error)
+
+var (
+	// schemesLock protects the schemes, so that they can be registered and
+	// used from multiple goroutines.
+	schemesLock sync.RWMutex
+	// schemes maps registered URI schemes (lower case) to their loaders.
+	schemes = map[string]SchemeLoader{}
+)
+
+// RegisterScheme registers a loader for URIs with the given scheme (e.g. "s3"
+// for "s3://bucket/config.yaml"); once registered, such URIs are accepted as
+// values by Unmarshal, UnmarshalInto and all the other functions in the
+// package, and passed to the loader along with the context of the call (see
+// UnmarshalContext). The maximum size of the data (see WithMaxFileSize) is
+// checked after the loader returns it. Registering a scheme again replaces
+// its loader. RegisterScheme panics if the scheme is one of the built-in ones
+// ("http", "https" and "file"), if it is not a valid scheme or if the loader
+// is nil.
+func RegisterScheme(scheme string, loader SchemeLoader) {
+	scheme = strings.ToLower(scheme)
+	switch scheme {
+	case "http", "https", "file":
+		panic(fmt.Sprintf("rawdata: RegisterScheme: cannot register built-in scheme %q", scheme))
+	}
+	if u, err := url.Parse(scheme + "://"); err != nil || u.Scheme != scheme {
+		panic(fmt.Sprintf("rawdata: RegisterScheme: invalid scheme %q", scheme))
+	}
+	if loader == nil {
+		panic("rawdata: RegisterScheme: loader is nil")
+	}
+	schemesLock.Lock()
+	defer schemesLock.Unlock()
+	schemes[scheme] = loader
+}
+
+// registeredScheme returns the loader registered for the scheme of the given
+// value, if it is a URI with a registered scheme.
+func registeredScheme(value string) (SchemeLoader, bool) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, false
+	}
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+	loader, ok := schemes[strings.ToLower(scheme)]
+	return loader, ok
+}
+
+// loadScheme loads the data referred to by a URI with a registered scheme.
+func loadScheme(loader SchemeLoader, uri string, o *options) (Format, []byte, error) {
+	format, content, err := loader(o.context(), uri)
+	if err != nil {
+		return FormatUnknown, nil, fmt.Errorf("error loading '%s': %w", uri, err)
+	}
+	if o.maxFileSize > 0 && int64(len(content)) > o.maxFileSize {
+		return FormatUnknown, nil, fmt.Errorf("data from '%s' is %d bytes, limit is %d: %w", uri, len(content), o.maxFileSize, ErrFileTooLarge)
+	}
+	return format, content, nil
+}
+
+// isFileURI returns whether the value is a "file://" URI.
+func isFileURI(value string) bool {
+	return len(value) >= len("file://") && strings.EqualFold(value[:len("file://")], "file://")
+}
+
+// fileURIPath returns the path of the local file referred to by a "file://"
+// URI, which must be absolute (e.g. "file:///etc/app.yaml"); the only host
+// allowed is "localhost".
+func fileURIPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI '%s': %w", uri, err)
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "", fmt.Errorf("invalid URI '%s': only local files are supported", uri)
+	}
+	if u.Path == "" {
+		return "", fmt.Errorf("invalid URI '%s': no path", uri)
+	}
+	return u.Path, nil
+}
@@ -0,0 +1,103 @@
+package rawdata
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegisterScheme(t *testing.T) {
+	objects := map[string]string{
+		"mem://bucket/config.yaml": "name: John\nage: 23",
+		"mem://bucket/config":      `{"name": "John", "age": 23}`,
+	}
+	RegisterScheme("MEM", func(ctx context.Context, uri string) (Format, []byte, error) {
+		if err := ctx.Err(); err != nil {
+			return FormatUnknown, nil, err
+		}
+		content, ok := objects[uri]
+		if !ok {
+			return FormatUnknown, nil, ErrFileNotFound
+		}
+		if strings.HasSuffix(uri, ".yaml") {
+			return FormatYAML, []byte(content), nil
+		}
+		return FormatUnknown, []byte(content), nil
+	})
+	defer func() {
+		schemesLock.Lock()
+		delete(schemes, "mem")
+		schemesLock.Unlock()
+	}()
+	for uri, expected := range map[string]Format{
+		"mem://bucket/config.yaml": FormatYAML,
+		"mem://bucket/config":      FormatJSON,
+	} {
+		result, format, err := UnmarshalWithFormat(uri)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", uri, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", uri, expected, format)
+		}
+		if result.(map[string]interface{})["name"] != "John" {
+			t.Errorf("invalid result for %q: %v", uri, result)
+		}
+	}
+	if _, err := Unmarshal("mem://bucket/missing"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for missing object: %v", err)
+	}
+	if _, err := Unmarshal("mem://bucket/config", WithMaxFileSize(8)); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("invalid error for oversized object: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := UnmarshalContext(ctx, "mem://bucket/config"); !errors.Is(err, context.Canceled) {
+		t.Errorf("invalid error for cancelled context: %v", err)
+	}
+}
+
+func TestRegisterSchemePanics(t *testing.T) {
+	loader := func(ctx context.Context, uri string) (Format, []byte, error) { return FormatUnknown, nil, nil }
+	for scheme, l := range map[string]SchemeLoader{
+		"https":  loader,
+		"file":   loader,
+		"not s3": loader,
+		"s3":     nil,
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic registering scheme %q", scheme)
+				}
+			}()
+			RegisterScheme(scheme, l)
+		}()
+	}
+}
+
+func TestUnmarshalFileURI(t *testing.T) {
+	filename, err := filepath.Abs("test/struct.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(filename)
+	result, format, err := UnmarshalWithFormat(uri)
+	if err != nil {
+		t.Fatalf("error unmarshalling %q: %v", uri, err)
+	}
+	if format != FormatJSON || result == nil {
+		t.Errorf("invalid result for %q: %v (%v)", uri, result, format)
+	}
+	if _, err := Unmarshal("file://localhost" + filepath.ToSlash(filename)); err != nil {
+		t.Errorf("error unmarshalling URI with localhost: %v", err)
+	}
+	if _, err := Unmarshal(uri, WithAllowFileAccess(false)); !errors.Is(err, ErrFileAccessDisabled) {
+		t.Errorf("invalid error with file access disabled: %v", err)
+	}
+	if _, err := Unmarshal("file://example.com/etc/app.yaml"); err == nil {
+		t.Error("no error for remote file URI")
+	}
+}
@@ -0,0 +1,105 @@
+package rawdata
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Shape is the kind of value at the top level of a document.
+type Shape uint8
+
+const (
+	// ShapeUnknown indicates that the shape could not be determined, e.g.
+	// because the document is empty or has a registered format.
+	ShapeUnknown Shape = iota
+	// ShapeObject indicates that the document is an object (a map).
+	ShapeObject
+	// ShapeArray indicates that the document is an array.
+	ShapeArray
+	// ShapeScalar indicates that the document is a single scalar value (see
+	// WithAllowScalars).
+	ShapeScalar
+)
+
+// String returns the name of the shape, e.g. "object".
+func (s Shape) String() string {
+	switch s {
+	case ShapeUnknown:
+		return "unknown"
+	case ShapeObject:
+		return "object"
+	case ShapeArray:
+		return "array"
+	case ShapeScalar:
+		return "scalar"
+	default:
+		return fmt.Sprintf("Shape(%d)", uint8(s))
+	}
+}
+
+// DetectShape reads the given value (see ReadContent) and returns its format
+// and whether the document is an object or an array, without unmarshalling
+// it: JSON is classified by its first character, YAML by the root node of
+// the document; TOML, properties and XML documents are always objects, CSV
+// and NDJSON documents always arrays.
+func DetectShape(value string, opts ...Option) (Format, Shape, error) {
+	o := newOptions(opts...)
+	format, content, err := readContent(value, o)
+	if err != nil {
+		return format, ShapeUnknown, err
+	}
+	return format, detectShape(format, content, o), nil
+}
+
+// detectShape returns the shape of the document in the given format; the
+// content is not validated, so malformed data may be reported with the shape
+// it appears to have.
+func detectShape(format Format, content []byte, o *options) Shape {
+	if isEmpty(content) {
+		return ShapeUnknown
+	}
+	switch format {
+	case FormatJSON:
+		return jsonShape(prepareJSON(content, o))
+	case FormatYAML:
+		document := yaml.Node{}
+		if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 {
+			return ShapeUnknown
+		}
+		root := document.Content[0]
+		if root.Kind == yaml.AliasNode {
+			root = root.Alias
+		}
+		switch root.Kind {
+		case yaml.MappingNode:
+			return ShapeObject
+		case yaml.SequenceNode:
+			return ShapeArray
+		case yaml.ScalarNode:
+			return ShapeScalar
+		}
+		return ShapeUnknown
+	case FormatTOML, FormatProperties, FormatXML:
+		return ShapeObject
+	case FormatCSV, FormatNDJSON:
+		return ShapeArray
+	default:
+		return ShapeUnknown
+	}
+}
+
+// jsonShape returns the shape of a JSON document by peeking at its first
+// non-whitespace character.
+func jsonShape(content []byte) Shape {
+	switch firstByte(content) {
+	case '{':
+		return ShapeObject
+	case '[':
+		return ShapeArray
+	case 0:
+		return ShapeUnknown
+	default:
+		return ShapeScalar
+	}
+}
@@ -0,0 +1,40 @@
+package rawdata
+
+import (
+	"testing"
+)
+
+func TestDetectShape(t *testing.T) {
+	testCases := []struct {
+		value  string
+		opts   []Option
+		format Format
+		shape  Shape
+	}{
+		{value: `{"a": 1}`, format: FormatJSON, shape: ShapeObject},
+		{value: "  \n[1, 2, 3]", format: FormatJSON, shape: ShapeArray},
+		{value: "json:42", format: FormatJSON, shape: ShapeScalar},
+		{value: "---\na: 1\n", format: FormatYAML, shape: ShapeObject},
+		{value: "---\n- 1\n", format: FormatYAML, shape: ShapeArray},
+		{value: "yaml:hello", format: FormatYAML, shape: ShapeScalar},
+		{value: "yaml:", opts: []Option{WithAllowEmpty(true)}, format: FormatYAML, shape: ShapeUnknown},
+		{value: "@./test/struct.toml", format: FormatTOML, shape: ShapeObject},
+		{value: "csv:a,b\n1,2\n", format: FormatCSV, shape: ShapeArray},
+		{value: "ndjson:{\"a\": 1}\n", format: FormatNDJSON, shape: ShapeArray},
+	}
+	for _, test := range testCases {
+		format, shape, err := DetectShape(test.value, test.opts...)
+		if err != nil {
+			t.Fatalf("error detecting shape of %q: %v", test.value, err)
+		}
+		if format != test.format || shape != test.shape {
+			t.Errorf("invalid result for %q: expected %v %v, got %v %v", test.value, test.format, test.shape, format, shape)
+		}
+	}
+	if _, _, err := DetectShape("@./test/missing.json"); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if ShapeArray.String() != "array" || Shape(42).String() != "Shape(42)" {
+		t.Errorf("invalid shape names: %v, %v", ShapeArray, Shape(42))
+	}
+}
@@ -0,0 +1,67 @@
+package rawdata
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// sourcePrefix is the prefix of references to in-memory sources (see
+// RegisterSource), e.g. "@mem:defaults".
+const sourcePrefix = "@mem:"
+
+// source is the data of an in-memory source.
+type source struct {
+	format  Format
+	content []byte
+}
+
+var (
+	// sourcesLock protects the sources, so that they can be registered and
+	// read from multiple goroutines.
+	sourcesLock sync.RWMutex
+	// sources maps the names of registered in-memory sources to their data.
+	sources = map[string]source{}
+)
+
+// RegisterSource registers an in-memory source with the given name, so that
+// "@mem:<name>" can be used as a value wherever a file reference can, e.g.
+// Unmarshal("@mem:defaults"), without touching any filesystem; this comes in
+// handy for tests and for data assembled at runtime. If the format is
+// FormatUnknown, it is detected from the extension in the name, if any, or
+// else from the data. Registering a name again replaces its source, while
+// registering nil content removes it. Since in-memory sources are registered
+// by the application itself, they can be read even when file access is
+// disabled (see WithAllowFileAccess).
+func RegisterSource(name string, format Format, content []byte) {
+	sourcesLock.Lock()
+	defer sourcesLock.Unlock()
+	if content == nil {
+		delete(sources, name)
+		return
+	}
+	sources[name] = source{format: format, content: append([]byte(nil), content...)}
+}
+
+// isSource returns whether the value is a reference to an in-memory source.
+func isSource(value string) bool {
+	return strings.HasPrefix(value, sourcePrefix)
+}
+
+// loadSource returns the data of the in-memory source the value refers to.
+func loadSource(value string, o *options) (Format, []byte, error) {
+	name := strings.TrimPrefix(value, sourcePrefix)
+	sourcesLock.RLock()
+	s, ok := sources[name]
+	sourcesLock.RUnlock()
+	if !ok {
+		return FormatUnknown, nil, fmt.Errorf("source '%s' is not registered: %w", name, ErrFileNotFound)
+	}
+	format := s.format
+	if format == FormatUnknown {
+		format = formatFromExtension(path.Ext(name), o)
+	}
+	// the caller must not be able to modify the registered data
+	return format, append([]byte(nil), s.content...), nil
+}
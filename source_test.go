@@ -0,0 +1,57 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestRegisterSource(t *testing.T) {
+	RegisterSource("defaults", FormatYAML, []byte("name: John\nage: 23\n"))
+	RegisterSource("app.json", FormatUnknown, []byte(`{"name": "Jane"}`))
+	RegisterSource("sniffed", FormatUnknown, []byte(`["a", "b"]`))
+	defer func() {
+		for _, name := range []string{"defaults", "app.json", "sniffed"} {
+			RegisterSource(name, FormatUnknown, nil)
+		}
+	}()
+	for value, expected := range map[string]interface{}{
+		"@mem:defaults": map[string]interface{}{"name": "John", "age": 23},
+		"@mem:app.json": map[string]interface{}{"name": "Jane"},
+		"@mem:sniffed":  []interface{}{"a", "b"},
+	} {
+		result, err := Unmarshal(value, WithAllowFileAccess(false))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", value, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for %q: %v", value, result)
+		}
+	}
+	target := &s{}
+	if err := UnmarshalInto("@mem:defaults", target); err != nil || target.Name != "John" {
+		t.Errorf("invalid target: %+v (error: %v)", target, err)
+	}
+	RegisterSource("defaults", FormatJSON, []byte(`{"name": "Jack"}`))
+	if result, err := Unmarshal("@mem:defaults"); err != nil || result.(map[string]interface{})["name"] != "Jack" {
+		t.Errorf("source not replaced: %v (error: %v)", result, err)
+	}
+	RegisterSource("defaults", FormatUnknown, nil)
+	if _, err := Unmarshal("@mem:defaults"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for removed source: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterSource("app.json", FormatJSON, []byte(`{"name": "Jane"}`))
+		}()
+		go func() {
+			defer wg.Done()
+			Unmarshal("@mem:app.json")
+		}()
+	}
+	wg.Wait()
+}
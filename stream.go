@@ -0,0 +1,226 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalStream unmarshals an array, calling fn for each of its elements in
+// order, with the element index and its value (as returned by Unmarshal);
+// unlike Unmarshal, it never holds more than one decoded element in memory,
+// so it can be used for large arrays. JSON and YAML data from standard input,
+// a file or a remote URL is decoded as it is read, so the maximum file size
+// (see WithMaxFileSize) does not apply to it; data that must be read in full
+// first, i.e. in other formats, in compressed files, or when it has to be
+// transformed, expanded or rendered as a template, is subject to the limit as
+// usual. For YAML, each document in the input must be an array and the
+// elements of all of them are streamed in order. If fn returns an error,
+// unmarshalling stops and the error is returned; data that is not an array
+// results in ErrNotArray.
+func UnmarshalStream(value string, fn func(index int, item interface{}) error, opts ...Option) error {
+	o := newOptions(opts...)
+	// open the data and detect its format
+	format, content, reader, err := openStream(value, o)
+	if err != nil {
+		return err
+	}
+	if reader != nil {
+		defer reader.Close()
+		text, err := decodeTextReader(contextReader{o.context(), reader})
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", describeValue(value), err)
+		}
+		if format == FormatJSON {
+			return streamJSON(text, nil, describeValue(value), fn, o)
+		}
+		return streamYAML(text, nil, describeValue(value), fn, o)
+	}
+	if isEmpty(content) {
+		return nil
+	}
+	switch format {
+	case FormatJSON:
+		content = prepareJSON(content, o)
+		return streamJSON(bytes.NewReader(content), content, describeValue(value), fn, o)
+	case FormatYAML:
+		return streamYAML(bytes.NewReader(content), content, describeValue(value), fn, o)
+	default:
+		result, err := decode(format, content, o)
+		if err != nil {
+			return err
+		}
+		array, ok := result.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot stream %s data: %w", formatLabel(format), ErrNotArray)
+		}
+		for index, item := range array {
+			if err := fn(index, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// openStream opens the data for streaming: if it comes from standard input,
+// a file or a remote URL and it can be decoded as it is read (see
+// isStreamable), it returns its format and a reader of the raw data, which the
+// caller must close; otherwise it returns the data read in full, as
+// readContent does.
+func openStream(value string, o *options) (Format, []byte, io.ReadCloser, error) {
+	if o.contentTransform == nil && !o.expandEnv && !o.template {
+		forced, source := cutFormatPrefix(value)
+		// percent-encoded paths are left to readContent to decode
+		encoded := o.decodePath && !isFileURI(source) && strings.ContainsRune(source, '%')
+		if isFileURI(source) {
+			if filename, err := fileURIPath(source); err == nil {
+				source = "@" + filename
+			}
+		}
+		var detected Format
+		var reader io.ReadCloser
+		var err error
+		switch {
+		case source == "@-" && !o.requireFile:
+			reader = io.NopCloser(stdin)
+		case strings.HasPrefix(source, "@") && !encoded && o.allowFileAccess && !isSource(source):
+			if _, _, ok := cutArchiveMember(source); ok {
+				break
+			}
+			filename := resolvePath(strings.TrimPrefix(source, "@"), o)
+			format := forced
+			if format == FormatUnknown {
+				format = formatFromExtension(path.Ext(filename), o)
+			}
+			if isStreamable(format, o) && !isGlob(filename, o) && !isDirectory(filename, o) && !strings.EqualFold(path.Ext(filename), ".gz") {
+				var file fs.File
+				if file, _, err = openFile(filename, o); err != nil {
+					return FormatUnknown, nil, nil, err
+				}
+				return format, nil, file, nil
+			}
+		case (strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")) && o.remoteAccess():
+			if detected, reader, err = openRemote(source, o); err != nil {
+				return FormatUnknown, nil, nil, err
+			}
+		}
+		if reader != nil {
+			format := forced
+			if format == FormatUnknown {
+				format = detected
+			}
+			if isStreamable(format, o) {
+				return format, nil, reader, nil
+			}
+			// the format must be detected from the data, or cannot be streamed
+			defer reader.Close()
+			content, err := readAll(contextReader{o.context(), reader}, o.maxFileSize)
+			if err != nil {
+				return FormatUnknown, nil, nil, fmt.Errorf("error reading %s: %w", describeSource(source), err)
+			}
+			format, content, err = detectFormat(forced, detected, content, source, o)
+			return format, content, nil, err
+		}
+	}
+	format, content, err := readContent(value, o)
+	return format, content, nil, err
+}
+
+// isStreamable returns whether data in the given format can be decoded as it
+// is read: JSON, unless comments or trailing commas have to be stripped from
+// it first, and YAML.
+func isStreamable(format Format, o *options) bool {
+	return format == FormatYAML || format == FormatJSON && !o.allowComments && !o.allowTrailingCommas
+}
+
+// streamJSON decodes the elements of a JSON array one at a time, as they are
+// read; content is the whole data if it was read in full, and is only used to
+// locate errors, whereas source describes where the data comes from.
+func streamJSON(reader io.Reader, content []byte, source string, fn func(index int, item interface{}) error, o *options) error {
+	decoder := json.NewDecoder(reader)
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return emptyStream(source, o)
+	} else if err != nil {
+		return newDecodeError(FormatJSON, content, err)
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("cannot stream JSON data: %w", ErrNotArray)
+	}
+	for index := 0; decoder.More(); index++ {
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			return newDecodeError(FormatJSON, content, err)
+		}
+		item, err := decode(FormatJSON, raw, o)
+		if err != nil {
+			return err
+		}
+		if err := fn(index, item); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return newDecodeError(FormatJSON, content, err)
+	}
+	// like json.Unmarshal, reject trailing data after the array
+	if _, err := decoder.Token(); err != io.EOF {
+		return newDecodeError(FormatJSON, content, errTrailingData)
+	}
+	return nil
+}
+
+// streamYAML decodes the elements of the arrays in a YAML stream one at a
+// time, as they are read; content and source are as in streamJSON.
+func streamYAML(reader io.Reader, content []byte, source string, fn func(index int, item interface{}) error, o *options) error {
+	decoder := yaml.NewDecoder(reader)
+	index := 0
+	for documents := 0; ; documents++ {
+		document := yaml.Node{}
+		if err := decoder.Decode(&document); err == io.EOF {
+			if documents == 0 {
+				return emptyStream(source, o)
+			}
+			return nil
+		} else if err != nil {
+			return newDecodeError(FormatYAML, content, err)
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		root := document.Content[0]
+		if root.Kind == yaml.AliasNode {
+			root = root.Alias
+		}
+		if root.Kind != yaml.SequenceNode {
+			return fmt.Errorf("cannot stream YAML data: %w", ErrNotArray)
+		}
+		for _, element := range root.Content {
+			item, err := decodeYAMLDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{element}}, o)
+			if err != nil {
+				return err
+			}
+			if err := fn(index, item); err != nil {
+				return err
+			}
+			index++
+		}
+	}
+}
+
+// emptyStream returns the result of streaming data that turns out to be
+// empty: nothing is streamed, which is an error unless empty data is allowed
+// (see WithAllowEmpty).
+func emptyStream(source string, o *options) error {
+	if !o.allowEmpty {
+		return fmt.Errorf("no data in %s: %w", source, ErrEmptyContent)
+	}
+	return nil
+}
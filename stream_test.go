@@ -0,0 +1,122 @@
+package rawdata
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalStream(t *testing.T) {
+	for _, value := range []string{
+		"@./test/array.json",
+		"@./test/array.yaml",
+		"---\n- one\n---\n- two\n- three\n",
+		"csv:one,two,three",
+	} {
+		items := []interface{}{}
+		err := UnmarshalStream(value, func(index int, item interface{}) error {
+			if index != len(items) {
+				t.Errorf("invalid index for %q: expected %d, got %d", value, len(items), index)
+			}
+			items = append(items, item)
+			return nil
+		}, WithCSVHeader(false))
+		if err != nil {
+			t.Fatalf("error streaming %q: %v", value, err)
+		}
+		if len(items) == 0 {
+			t.Errorf("no items streamed from %q", value)
+		}
+	}
+
+	items := []interface{}{}
+	err := UnmarshalStream(`[{"name": "John"}, 2, "three"]`, func(index int, item interface{}) error {
+		items = append(items, item)
+		return nil
+	})
+	expected := []interface{}{map[string]interface{}{"name": "John"}, float64(2), "three"}
+	if err != nil || !reflect.DeepEqual(items, expected) {
+		t.Errorf("invalid items: %v (error: %v)", items, err)
+	}
+}
+
+func TestUnmarshalStreamErrors(t *testing.T) {
+	stop := errors.New("stop")
+	count := 0
+	err := UnmarshalStream("[1, 2, 3]", func(index int, item interface{}) error {
+		count++
+		if index == 1 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 2 {
+		t.Errorf("invalid result stopping the stream: %v after %d items", err, count)
+	}
+
+	for _, value := range []string{`{"name": "John"}`, "---\nname: John\n", "@./test/struct.toml"} {
+		if err := UnmarshalStream(value, func(int, interface{}) error { return nil }); !errors.Is(err, ErrNotArray) {
+			t.Errorf("invalid error streaming %q: %v", value, err)
+		}
+	}
+
+	var decodeError *DecodeError
+	if err := UnmarshalStream("[1, 2,, 3]", func(int, interface{}) error { return nil }); !errors.As(err, &decodeError) {
+		t.Errorf("invalid error streaming invalid JSON: %v", err)
+	}
+	if err := UnmarshalStream("[1, 2] 3", func(int, interface{}) error { return nil }); !errors.As(err, &decodeError) {
+		t.Errorf("invalid error streaming JSON with trailing data: %v", err)
+	}
+}
+
+func TestUnmarshalStreamLargerThanLimit(t *testing.T) {
+	dir := t.TempDir()
+	elements := make([]string, 1000)
+	for i := range elements {
+		elements[i] = strconv.Itoa(i)
+	}
+	files := map[string]string{
+		"large.json": "[" + strings.Join(elements, ", ") + "]",
+		"large.yaml": "- " + strings.Join(elements, "\n- ") + "\n",
+	}
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		err := UnmarshalStream("@"+filename, func(index int, item interface{}) error {
+			count++
+			return nil
+		}, WithMaxFileSize(100))
+		if err != nil || count != len(elements) {
+			t.Errorf("error streaming %s beyond the size limit: %d items (error: %v)", name, count, err)
+		}
+		// data that must be read in full is still subject to the limit
+		err = UnmarshalStream("@"+filename, func(int, interface{}) error { return nil }, WithMaxFileSize(100), WithEnvExpansion(true))
+		if !errors.Is(err, ErrFileTooLarge) {
+			t.Errorf("invalid error streaming %s with expansion: %v", name, err)
+		}
+	}
+
+	defer func(r io.Reader) { stdin = r }(stdin)
+	stdin = strings.NewReader("\xEF\xBB\xBF" + files["large.json"])
+	count := 0
+	err := UnmarshalStream("json:@-", func(index int, item interface{}) error {
+		count++
+		return nil
+	}, WithMaxFileSize(100))
+	if err != nil || count != len(elements) {
+		t.Errorf("error streaming standard input beyond the size limit: %d items (error: %v)", count, err)
+	}
+
+	stdin = strings.NewReader(" \n")
+	if err := UnmarshalStream("json:@-", func(int, interface{}) error { return nil }); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("invalid error streaming empty data: %v", err)
+	}
+}
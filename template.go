@@ -0,0 +1,42 @@
+package rawdata
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// TemplateError is returned when the data cannot be rendered as a template
+// (see WithTemplate), either because the template cannot be parsed or because
+// it fails to execute; the underlying error (e.g. a template.ExecError) can be
+// inspected with errors.As.
+type TemplateError struct {
+	// Source describes where the data comes from.
+	Source string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("error rendering template in %s: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// renderTemplate renders the content as a text/template with the data and
+// the functions set in the options.
+func renderTemplate(source string, content []byte, o *options) ([]byte, error) {
+	tmpl, err := template.New(source).Option("missingkey=error").Funcs(o.templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, &TemplateError{Source: source, Err: err}
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, o.templateData); err != nil {
+		return nil, &TemplateError{Source: source, Err: err}
+	}
+	return buffer.Bytes(), nil
+}
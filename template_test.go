@@ -0,0 +1,60 @@
+package rawdata
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestWithTemplate(t *testing.T) {
+	input := `{{- if .Production }}---
+replicas: 3
+{{- else }}{"replicas": 1
+{{- end }}
+{{- if .Production }}
+region: {{ .Region | upper }}
+zones:
+{{- range .Zones }}
+  - {{ . }}
+{{- end }}
+{{- else }}, "region": "{{ .Region }}"}{{ end }}
+`
+	data := map[string]interface{}{"Production": true, "Region": "eu-west-1", "Zones": []string{"a", "b"}}
+	result, format, err := UnmarshalWithFormat(input, WithTemplate(data), WithTemplateFuncs(template.FuncMap{"upper": strings.ToUpper}))
+	if err != nil {
+		t.Fatalf("error unmarshalling template: %v", err)
+	}
+	expected := map[string]interface{}{"replicas": 3, "region": "EU-WEST-1", "zones": []interface{}{"a", "b"}}
+	if format != FormatYAML || !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v (%v)", result, format)
+	}
+	data["Production"] = false
+	result, format, err = UnmarshalWithFormat(input, WithTemplate(data), WithTemplateFuncs(template.FuncMap{"upper": strings.ToUpper}))
+	if err != nil {
+		t.Fatalf("error unmarshalling template: %v", err)
+	}
+	expected = map[string]interface{}{"replicas": 1.0, "region": "eu-west-1"}
+	if format != FormatJSON || !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v (%v)", result, format)
+	}
+	if result, _ := Unmarshal(`{"region": "{{ .Region }}"}`); result.(map[string]interface{})["region"] != "{{ .Region }}" {
+		t.Errorf("template rendered by default: %v", result)
+	}
+}
+
+func TestWithTemplateErrors(t *testing.T) {
+	var templateError *TemplateError
+	if _, err := Unmarshal(`{"region": "{{ .Region "}`, WithTemplate(nil)); !errors.As(err, &templateError) {
+		t.Errorf("invalid error for malformed template: %v", err)
+	}
+	if _, err := Unmarshal(`{"region": "{{ .Region }}"}`, WithTemplate(map[string]string{})); !errors.As(err, &templateError) {
+		t.Errorf("invalid error for missing key: %v", err)
+	} else if !errors.As(err, new(template.ExecError)) {
+		t.Errorf("execution error not wrapped: %v", err)
+	}
+	if _, err := Unmarshal(`{"region": {{ .Region }}}`, WithTemplate(map[string]string{"Region": "eu"})); errors.As(err, &templateError) || !errors.As(err, new(*DecodeError)) {
+		t.Errorf("invalid error for invalid rendered data: %v", err)
+	}
+}
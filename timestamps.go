@@ -0,0 +1,197 @@
+package rawdata
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeLayouts are the layouts accepted for times when custom handling
+// of times is enabled (see WithTimeLayouts and WithParseTimestamps): RFC 3339
+// (with or without fractional seconds), the same without the time zone (taken
+// as UTC), with a space instead of the "T", and plain dates.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// timeType is the type of time.Time values.
+var timeType = reflect.TypeOf(time.Time{})
+
+// parseTime parses the value with the first matching time layout, trying the
+// default ones first.
+func parseTime(value string, o *options) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layouts := range [][]string{DefaultTimeLayouts, o.timeLayouts} {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimestamps replaces the strings in the decoded value that match any of
+// the accepted time layouts with the corresponding time.Time values.
+func parseTimestamps(v interface{}, o *options) interface{} {
+	switch v := v.(type) {
+	case string:
+		if t, ok := parseTime(v, o); ok {
+			return t
+		}
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = parseTimestamps(value, o)
+		}
+	case *OrderedMap:
+		for _, key := range v.Keys() {
+			value, _ := v.Get(key)
+			v.Set(key, parseTimestamps(value, o))
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = parseTimestamps(value, o)
+		}
+	}
+	return v
+}
+
+// normaliseYAML rewrites the scalars in the YAML document that the YAML
+// library would not decode as intended into the target: those bound for
+// time.Time values that match any of the accepted time layouts become RFC
+// 3339 timestamps, which the YAML library understands, and those bound for
+// interface{} values that are YAML 1.1 booleans become booleans (see
+// WithYAMLBoolCompat); it returns the content of the document, re-encoded if
+// anything changed.
+func normaliseYAML(document *yaml.Node, content []byte, target interface{}, o *options) ([]byte, error) {
+	normaliser := yamlNormaliser{o: o, visited: map[*yaml.Node]bool{}}
+	for _, node := range document.Content {
+		normaliser.walk(node, reflect.TypeOf(target))
+	}
+	if !normaliser.changed {
+		return content, nil
+	}
+	content, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("error normalising YAML data: %w", err)
+	}
+	return content, nil
+}
+
+// yamlNormaliser walks a YAML node tree along with the type that it is going
+// to be decoded into.
+type yamlNormaliser struct {
+	o       *options
+	visited map[*yaml.Node]bool
+	changed bool
+}
+
+// unmarshalerTypes are the interfaces through which types take care of their
+// own decoding; the normaliser leaves the nodes bound for them alone.
+var unmarshalerTypes = []reflect.Type{
+	reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+}
+
+// walk normalises the node, which is bound for a value of the given type.
+func (n *yamlNormaliser) walk(node *yaml.Node, t reflect.Type) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || n.visited[node] {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		n.walk(node.Alias, t)
+		return
+	}
+	if t.Kind() == reflect.Interface {
+		if n.o.yamlBoolCompat && convertYAMLBools(node, n.visited) {
+			n.changed = true
+		}
+		return
+	}
+	if t == timeType {
+		if n.o.timeLayouts != nil && node.Kind == yaml.ScalarNode && node.Tag != "!!null" {
+			if parsed, ok := parseTime(node.Value, n.o); ok {
+				node.Value = parsed.Format(time.RFC3339Nano)
+				node.Tag = "!!timestamp"
+				node.Style = 0
+				n.changed = true
+			}
+		}
+		return
+	}
+	for _, unmarshaler := range unmarshalerTypes {
+		if reflect.PtrTo(t).Implements(unmarshaler) {
+			return
+		}
+	}
+	// the same node can be reached through several aliases, but it is only
+	// bound for one type in practice
+	n.visited[node] = true
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if field, ok := fields[node.Content[i].Value]; ok {
+				n.walk(node.Content[i+1], field)
+			} else if node.Content[i].Tag == "!!merge" {
+				n.walk(node.Content[i+1], t)
+			}
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			n.walk(node.Content[i+1], t.Elem())
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			n.walk(item, t.Elem())
+		}
+	case t.Kind() == reflect.Struct && node.Kind == yaml.SequenceNode:
+		// a sequence of mappings merged into a struct
+		for _, item := range node.Content {
+			n.walk(item, t)
+		}
+	}
+}
+
+// yamlFields returns the types of the fields of the struct type, by the keys
+// they are decoded from, following the rules of the YAML library: the key is
+// taken from the "yaml" tag or else is the lower-cased field name, and the
+// fields of inlined structs are included.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+flags+",", ",inline,") {
+			if field.Type.Kind() == reflect.Struct {
+				for key, value := range yamlFields(field.Type) {
+					fields[key] = value
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,76 @@
+package rawdata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithTimeLayouts(t *testing.T) {
+	type event struct {
+		Name      string     `yaml:"name"`
+		Label     string     `yaml:"label"`
+		Start     time.Time  `yaml:"start"`
+		End       *time.Time `yaml:"end"`
+		Reminders []time.Time
+	}
+	input := `---
+name: launch
+label: 2024-03-01 09:30
+start: 2024-03-01 09:30
+end: "2024-03-01T18:00:00"
+reminders:
+  - 01/03/2024
+  - 2024-02-28
+`
+	target := event{}
+	if err := UnmarshalInto(input, &target); err == nil {
+		t.Fatal("no error unmarshalling non-standard times without custom layouts")
+	}
+	target = event{}
+	if err := UnmarshalInto(input, &target, WithTimeLayouts([]string{"2006-01-02 15:04", "02/01/2006"})); err != nil {
+		t.Fatalf("error unmarshalling times with custom layouts: %v", err)
+	}
+	if expected := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC); !target.Start.Equal(expected) {
+		t.Errorf("invalid start: expected %v, got %v", expected, target.Start)
+	}
+	if expected := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC); target.End == nil || !target.End.Equal(expected) {
+		t.Errorf("invalid end: expected %v, got %v", expected, target.End)
+	}
+	if len(target.Reminders) != 2 || !target.Reminders[0].Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("invalid reminders: %v", target.Reminders)
+	}
+	if target.Label != "2024-03-01 09:30" {
+		t.Errorf("invalid label: %q", target.Label)
+	}
+}
+
+func TestWithParseTimestamps(t *testing.T) {
+	input := `{"name": "launch", "start": "2024-03-01T09:30:00+01:00", "days": ["2024-03-01", "2024-03-02"], "label": "03/01"}`
+	result, err := Unmarshal(input, WithParseTimestamps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling with timestamps: %v", err)
+	}
+	object := result.(map[string]interface{})
+	if start, ok := object["start"].(time.Time); !ok || !start.Equal(time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("invalid start: %v", object["start"])
+	}
+	if days := object["days"].([]interface{}); len(days) != 2 {
+		t.Errorf("invalid days: %v", days)
+	} else if _, ok := days[1].(time.Time); !ok {
+		t.Errorf("invalid day: %v", days[1])
+	}
+	if object["name"] != "launch" || object["label"] != "03/01" {
+		t.Errorf("invalid strings: %v", object)
+	}
+	result, err = Unmarshal(input, WithParseTimestamps(true), WithTimeLayouts([]string{"01/02"}), WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling with custom layout: %v", err)
+	}
+	if label, _ := result.(*OrderedMap).Get("label"); label != time.Date(0, 3, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("invalid label: %v", label)
+	}
+	result, _ = Unmarshal(input)
+	if _, ok := result.(map[string]interface{})["start"].(string); !ok {
+		t.Errorf("timestamp converted by default: %v", result)
+	}
+}
@@ -0,0 +1,175 @@
+package rawdata
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// reportUnknownKeys calls the handler set via WithUnknownKeyHandler with the
+// path of each key in the data that does not match any field in the target,
+// following the rules of the library the data is decoded with: "yaml" tags
+// and exact matches for YAML, "toml" tags for TOML and "json" tags for JSON
+// and the formats decoded via their JSON representation, with keys matching
+// field names regardless of case for the latter two. XML and registered
+// formats are not checked.
+func reportUnknownKeys(format Format, content []byte, target interface{}, o *options) error {
+	var tag string
+	switch format {
+	case FormatJSON, FormatNDJSON, FormatCSV, FormatProperties:
+		tag = "json"
+	case FormatYAML:
+		tag = "yaml"
+	case FormatTOML:
+		tag = "toml"
+	default:
+		return nil
+	}
+	if isEmpty(content) {
+		return nil
+	}
+	generic := *o
+	generic.orderedMaps = false
+	value, err := decode(format, content, &generic)
+	if err != nil {
+		return err
+	}
+	walker := unknownKeyWalker{tag: tag, separator: o.separator, handler: o.unknownKey}
+	walker.walk(value, reflect.TypeOf(target), "")
+	return nil
+}
+
+// unknownKeyWalker walks a generic value along with the type that it is
+// going to be decoded into, looking for keys with no matching field.
+type unknownKeyWalker struct {
+	tag       string
+	separator string
+	handler   func(path string)
+}
+
+// unmarshalerInterfaces are the interfaces through which types take care of
+// their own decoding, so that any key is acceptable to them.
+var unmarshalerInterfaces = []reflect.Type{
+	reflect.TypeOf((*json.Unmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*toml.Unmarshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem(),
+}
+
+// walk reports the unknown keys in the value, which is bound for a value of
+// the given type and is found at the given path.
+func (w *unknownKeyWalker) walk(value interface{}, t reflect.Type, path string) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+	for _, unmarshaler := range unmarshalerInterfaces {
+		if reflect.PtrTo(t).Implements(unmarshaler) {
+			return
+		}
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		switch t.Kind() {
+		case reflect.Struct:
+			fields := w.fields(t)
+			for _, key := range keys {
+				if field, ok := w.lookup(fields, key); ok {
+					w.walk(value[key], field, joinPath(path, key, w.separator))
+				} else {
+					w.handler(joinPath(path, key, w.separator))
+				}
+			}
+		case reflect.Map:
+			for _, key := range keys {
+				w.walk(value[key], t.Elem(), joinPath(path, key, w.separator))
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range value {
+				w.walk(item, t.Elem(), joinPath(path, strconv.Itoa(i), w.separator))
+			}
+		}
+	case []map[string]interface{}:
+		// arrays of tables, in TOML
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range value {
+				w.walk(item, t.Elem(), joinPath(path, strconv.Itoa(i), w.separator))
+			}
+		}
+	}
+}
+
+// fields returns the types of the fields of the struct type, by the keys
+// they are decoded from.
+func (w *unknownKeyWalker) fields(t reflect.Type) map[string]reflect.Type {
+	if w.tag == "yaml" {
+		return yamlFields(t)
+	}
+	return taggedFields(t, w.tag)
+}
+
+// lookup returns the type of the field matching the key, if any; apart from
+// YAML, keys also match fields regardless of case.
+func (w *unknownKeyWalker) lookup(fields map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if field, ok := fields[key]; ok || w.tag == "yaml" {
+		return field, ok
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// taggedFields returns the types of the fields of the struct type, by the
+// keys they are decoded from, following the rules of the JSON library: the
+// key is taken from the given tag or else is the field name, and the fields
+// of embedded structs without a tag are included.
+func taggedFields(t reflect.Type, tag string) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, value := range taggedFields(embedded, tag) {
+					if _, ok := fields[key]; !ok {
+						fields[key] = value
+					}
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
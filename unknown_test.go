@@ -0,0 +1,69 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type unknownBase struct {
+	ID string `json:"id" yaml:"id" toml:"id"`
+}
+
+type unknownServer struct {
+	Host string `json:"host" yaml:"host" toml:"host"`
+	Port int    `json:"port" yaml:"port" toml:"port"`
+}
+
+type unknownConfig struct {
+	unknownBase `yaml:",inline"`
+	Name        string                   `json:"name" yaml:"name" toml:"name"`
+	Servers     []unknownServer          `json:"servers" yaml:"servers" toml:"servers"`
+	Labels      map[string]unknownServer `json:"labels" yaml:"labels" toml:"labels"`
+	Extra       interface{}              `json:"extra" yaml:"extra" toml:"extra"`
+	Timeout     time.Duration            `json:"-" yaml:"-" toml:"-"`
+	Started     *time.Time               `json:"started" yaml:"started" toml:"started"`
+}
+
+func TestWithUnknownKeyHandler(t *testing.T) {
+	testCases := map[string][]string{
+		`{"id": "x", "NAME": "a", "servers": [{"host": "h", "hostname": "h"}], "labels": {"a": {"prot": 1}}, "extra": {"any": 1}, "timeout": 5, "obsolete": true}`: {
+			"labels.a.prot", "obsolete", "servers.0.hostname", "timeout",
+		},
+		"---\nid: x\nNAME: a\nservers:\n  - host: h\n    hostname: h\nlabels:\n  a: {prot: 1}\nextra: {any: 1}\nobsolete: true\n": {
+			"NAME", "labels.a.prot", "obsolete", "servers.0.hostname",
+		},
+		"toml:id = 'x'\nNAME = 'a'\nobsolete = true\n[[servers]]\nhost = 'h'\nhostname = 'h'\n": {
+			"obsolete", "servers.0.hostname",
+		},
+		"properties:name = a\nserver.host = h\n": {
+			"server",
+		},
+		`{"name": "a", "started": "2024-01-01T00:00:00Z"}`: nil,
+	}
+	for input, expected := range testCases {
+		var paths []string
+		config := unknownConfig{}
+		if err := UnmarshalInto(input, &config, WithUnknownKeyHandler(func(path string) {
+			paths = append(paths, path)
+		})); err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if !reflect.DeepEqual(paths, expected) {
+			t.Errorf("invalid unknown keys for %q: expected %v, got %v", input, expected, paths)
+		}
+		if config.ID != "" && config.ID != "x" || len(config.Servers) > 0 && config.Servers[0].Host != "h" {
+			t.Errorf("invalid result for %q: %+v", input, config)
+		}
+	}
+	var paths []string
+	configs := []unknownServer{}
+	if err := UnmarshalInto(`[{"host": "a"}, {"hots": "b"}]`, &configs, WithSeparator("/"), WithUnknownKeyHandler(func(path string) {
+		paths = append(paths, path)
+	})); err != nil {
+		t.Fatalf("error unmarshalling array: %v", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"1/hots"}) {
+		t.Errorf("invalid unknown keys in array: %v", paths)
+	}
+}
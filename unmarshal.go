@@ -1,13 +1,23 @@
 package rawdata
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
+	"reflect"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
@@ -22,155 +32,1040 @@ const (
 	FormatJSON
 	// FormatYAML indicates that the flag is in YAML format.
 	FormatYAML
+	// FormatTOML indicates that the flag is in TOML format.
+	FormatTOML
+	// FormatCSV indicates that the flag is in CSV format.
+	FormatCSV
+	// FormatProperties indicates that the flag is in Java properties (or
+	// INI) format.
+	FormatProperties
+	// FormatXML indicates that the flag is in XML format.
+	FormatXML
+	// FormatNDJSON indicates that the flag is in newline-delimited JSON (or
+	// JSON Lines) format.
+	FormatNDJSON
 )
 
+// String returns the name of the format, e.g. "json".
+func (f Format) String() string {
+	switch f {
+	case FormatUnknown:
+		return "unknown"
+	case FormatJSON:
+		return "json"
+	case FormatYAML:
+		return "yaml"
+	case FormatTOML:
+		return "toml"
+	case FormatCSV:
+		return "csv"
+	case FormatProperties:
+		return "properties"
+	case FormatXML:
+		return "xml"
+	case FormatNDJSON:
+		return "ndjson"
+	default:
+		return fmt.Sprintf("Format(%d)", uint8(f))
+	}
+}
+
+// ParseFormat returns the format with the given name (as returned by
+// String); the match is case-insensitive, and "yml" and "jsonl" are accepted
+// as aliases for YAML and NDJSON. An unrecognised name results in
+// FormatUnknown and an error.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "unknown":
+		return FormatUnknown, nil
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	case "csv":
+		return FormatCSV, nil
+	case "properties", "ini":
+		return FormatProperties, nil
+	case "xml":
+		return FormatXML, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return FormatUnknown, fmt.Errorf("%w: '%s'", ErrUnsupportedFormat, s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so that formats are
+// serialised by name (e.g. as "json" rather than 1).
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so that formats can be
+// deserialised by name (see ParseFormat).
+func (f *Format) UnmarshalText(text []byte) error {
+	format, err := ParseFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
 // Unmarshal unmarshals a complex value into an object; if the value
 // starts with a '@' it is assumed to be a file on the local filesystem,
 // it is read into memory and then unmarshalled into a generic map or
 // array depending on the contents; if it does not start with '@', it
 // can be either a YAML inline representation (in which case it MUST
 // start with '---') or an inline JSON representation and is unmarshalled
-// accordingly.
-func Unmarshal(value string) (interface{}, error) {
+// accordingly. Inline TOML, which cannot be detected from the data, requires
+// an explicit format prefix (e.g. "toml:key = 'value'"); the same kind of
+// prefix can be used to override auto-detection for any input value (e.g.
+// "json:@myfile.conf"), see ReadContent.
+// The behaviour can be customised by passing one or more Options.
+func Unmarshal(value string, opts ...Option) (interface{}, error) {
+	result, _, err := UnmarshalWithFormat(value, opts...)
+	return result, err
+}
+
+// UnmarshalWithFormat is like Unmarshal, but it also returns the format that
+// was detected in the input value, e.g. to serialise the data back in the
+// same format later on.
+func UnmarshalWithFormat(value string, opts ...Option) (interface{}, Format, error) {
+	return unmarshalWithFormat(value, newOptions(opts...))
+}
+
+// unmarshalWithFormat is the implementation of UnmarshalWithFormat.
+func unmarshalWithFormat(value string, o *options) (interface{}, Format, error) {
 	// read data and detect its format
-	format, content, err := ReadContent(value)
+	format, content, err := readContent(value, o)
 	if err != nil {
-		return nil, err
+		return nil, format, err
 	}
-	// now depending on the format, unmarshal to JSON or YAML
-	switch format {
-	case FormatJSON:
-		return unmarshalJSON(content)
-	case FormatYAML:
-		return unmarshalYAML(content)
-	default:
-		return nil, fmt.Errorf("unsupported encoding: %v", format)
+	result, err := decodeContent(format, content, value, describeValue(value), o)
+	return result, format, err
+}
+
+// decodeContent decodes the content into a generic value and then applies
+// any processing required by the options; value is the input value the
+// content was read from, against which includes are resolved (empty if there
+// is none, e.g. for readers), and source describes it in error messages.
+func decodeContent(format Format, content []byte, value string, source string, o *options) (interface{}, error) {
+	result, err := decode(format, content, o)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", source, err)
+	}
+	if o.includes {
+		result, err = resolveIncludes(value, result, o)
+	}
+	if err == nil && o.schema != nil {
+		err = ValidateAgainstSchema(result, o.schema)
 	}
+	if err == nil && o.parseTimestamps {
+		result = parseTimestamps(result, o)
+	}
+	if err == nil && o.preserveComments && format == FormatYAML && !isEmpty(content) {
+		result, err = newDocument(content, o)
+	}
+	return result, err
 }
 
 // UnmarshalInto is a more type-contrained version of Unmarshal: it requires
 // the output object (either a struct or an array) to passed in as a pointer.
+// The target can also be a pointer to a pointer (e.g. **Config), to tell
+// absent data from zero values: if the data is null (or empty, see
+// WithAllowEmpty), the pointer is set to nil, otherwise it is allocated if nil
+// and the data is decoded into the value it points to.
 // The input value can either be an inline JSON/YAM value, or a reference to
-// a file (e.g. '@myfile.json') in JSON/YAML format.
-func UnmarshalInto(value string, target interface{}) error {
+// a file (e.g. '@myfile.json') in JSON/YAML/TOML format.
+func UnmarshalInto(value string, target interface{}, opts ...Option) error {
+	return unmarshalInto(value, target, newOptions(opts...))
+}
+
+// unmarshalInto is the implementation of UnmarshalInto.
+func unmarshalInto(value string, target interface{}, o *options) error {
 	// read data and detect its format
-	format, content, err := ReadContent(value)
+	format, content, err := readContent(value, o)
 	if err != nil {
 		return err
-	} // now depending on the format, unmarshal to JSON or YAML
+	}
+	return decodeContentInto(format, content, target, describeValue(value), o)
+}
+
+// decodeContentInto decodes the content into the given target, after
+// validating it, reporting unknown keys, applying defaults and running the
+// decode hooks as required by the options; source describes where the
+// content comes from in error messages.
+func decodeContentInto(format Format, content []byte, target interface{}, source string, o *options) error {
+	var err error
+	if o.schema != nil {
+		// validate the generic representation of the data first
+		result, err := decode(format, content, o)
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %w", source, err)
+		}
+		if err := ValidateAgainstSchema(result, o.schema); err != nil {
+			return err
+		}
+	}
+	if o.unknownKey != nil {
+		if err := reportUnknownKeys(format, content, target, o); err != nil {
+			return fmt.Errorf("error decoding %s: %w", source, err)
+		}
+	}
+	if o.defaults != nil {
+		if format, content, err = applyDefaults(format, content, o); err != nil {
+			return fmt.Errorf("error decoding %s: %w", source, err)
+		}
+	}
+	if len(o.decodeHooks) > 0 {
+		if format, content, err = applyDecodeHooks(format, content, target, o); err != nil {
+			return fmt.Errorf("error decoding %s: %w", source, err)
+		}
+	}
+	if err := decodeInto(format, content, target, o); err != nil {
+		return fmt.Errorf("error decoding %s: %w", source, err)
+	}
+	return nil
+}
+
+// decode unmarshals the content into a generic map or array, depending on
+// the format.
+func decode(format Format, content []byte, o *options) (interface{}, error) {
+	if isEmpty(content) && o.allowEmpty {
+		return nil, nil
+	}
+	if o.numberMode != NumberModeDefault {
+		// start from the literals, where the format has them; TOML numbers
+		// are typed already, and would lose their type as json.Numbers
+		exact := *o
+		exact.useNumber = format != FormatTOML
+		exact.numberMode = NumberModeDefault
+		result, err := decode(format, content, &exact)
+		if err != nil {
+			return nil, err
+		}
+		return normaliseNumbers(result, o.numberMode), nil
+	}
 	switch format {
 	case FormatJSON:
-		if err := json.Unmarshal(content, target); err != nil {
-			return fmt.Errorf("error unmarshalling from JSON: %w", err)
+		content = prepareJSON(content, o)
+		if err := checkJSONDepth(content, o); err != nil {
+			return nil, err
+		}
+		if o.orderedMaps {
+			return unmarshalOrderedJSON(content, o)
+		}
+		return unmarshalJSON(content, o)
+	case FormatYAML:
+		return unmarshalYAML(content, o)
+	case FormatTOML:
+		return o.limitDepth(unmarshalTOML(content, o))
+	case FormatCSV:
+		return o.limitDepth(unmarshalCSV(content, o))
+	case FormatProperties:
+		return o.limitDepth(unmarshalProperties(content, o))
+	case FormatXML:
+		return o.limitDepth(unmarshalXML(content, o))
+	case FormatNDJSON:
+		return unmarshalNDJSON(content, o)
+	default:
+		return o.limitDepth(decodeRegistered(format, content))
+	}
+}
+
+// decodeInto unmarshals the content into the given target, depending on
+// the format.
+func decodeInto(format Format, content []byte, target interface{}, o *options) error {
+	if v := reflect.ValueOf(target); v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+		return decodeIntoPointer(format, content, v.Elem(), o)
+	}
+	if isEmpty(content) && o.allowEmpty {
+		// leave the target untouched
+		return nil
+	}
+	if o.maxDepth > 0 && format != FormatJSON && format != FormatYAML {
+		// the depth can only be checked on the generic representation
+		if _, err := decode(format, content, o); err != nil {
+			return err
+		}
+	}
+	switch format {
+	case FormatJSON:
+		if err := checkJSONDepth(content, o); err != nil {
+			return err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(prepareJSON(content, o)))
+		if o.strict {
+			decoder.DisallowUnknownFields()
+		}
+		if o.useNumber {
+			decoder.UseNumber()
+		}
+		if err := decoder.Decode(target); err != nil {
+			return newDecodeError(FormatJSON, content, err)
+		}
+		// like json.Unmarshal, reject trailing data after the document
+		if _, err := decoder.Token(); err != io.EOF {
+			return newDecodeError(FormatJSON, content, errTrailingData)
 		}
 		return nil
 	case FormatYAML:
-		if err := yaml.Unmarshal(content, target); err != nil {
-			return fmt.Errorf("error unmarshalling from YAML: %w (%T)", err, err)
+		if o.maxDepth > 0 || o.maxAliasExpansion > 0 || o.timeLayouts != nil || o.yamlBoolCompat {
+			// the limits are checked on the node tree, before the decoder
+			// expands any aliases
+			document := yaml.Node{}
+			if err := yaml.Unmarshal(content, &document); err != nil {
+				return newDecodeError(FormatYAML, content, err)
+			}
+			if err := checkYAMLLimits(&document, o); err != nil {
+				return err
+			}
+			if o.timeLayouts != nil || o.yamlBoolCompat {
+				var err error
+				if content, err = normaliseYAML(&document, content, target, o); err != nil {
+					return err
+				}
+			}
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(o.strict)
+		if err := decoder.Decode(target); err != nil && err != io.EOF {
+			return withTargetType(newDecodeError(FormatYAML, content, err), target, "yaml")
+		}
+		return nil
+	case FormatTOML:
+		metadata, err := toml.Decode(string(content), target)
+		if err != nil {
+			return withTargetType(newDecodeError(FormatTOML, content, err), target, "toml")
+		}
+		if undecoded := metadata.Undecoded(); o.strict && len(undecoded) > 0 {
+			return newDecodeError(FormatTOML, content, fmt.Errorf("unknown field %q", undecoded[0].String()))
+		}
+		return nil
+	case FormatCSV:
+		result, err := unmarshalCSV(content, o)
+		if err != nil {
+			return err
+		}
+		return convertInto(result, target, format)
+	case FormatProperties:
+		result, err := unmarshalProperties(content, o)
+		if err != nil {
+			return err
+		}
+		return convertInto(result, target, format)
+	case FormatNDJSON:
+		result, err := unmarshalNDJSON(content, o)
+		if err != nil {
+			return err
+		}
+		return convertInto(result, target, format)
+	case FormatXML:
+		if err := xml.Unmarshal(content, target); err != nil {
+			return newDecodeError(FormatXML, content, err)
 		}
 		return nil
 	default:
-		return fmt.Errorf("unsupported encoding: %v", format)
+		return decodeRegisteredInto(format, content, target)
+	}
+}
+
+// convertInto converts a generic value decoded from the given format into the
+// target, via its JSON representation.
+func convertInto(value interface{}, target interface{}, format Format) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error converting %v data: %w", format, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		// the position in the JSON representation would be meaningless
+		return newDecodeError(format, nil, err)
+	}
+	return nil
+}
+
+// UnmarshalTyped is a generic version of UnmarshalInto: it allocates an
+// object of type T, unmarshals the value into it and returns it, e.g.
+//
+//	cfg, err := rawdata.UnmarshalTyped[AppConfig](value)
+//
+// On error it returns the zero value of T.
+func UnmarshalTyped[T any](value string, opts ...Option) (T, error) {
+	var result T
+	if err := UnmarshalInto(value, &result, opts...); err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// MustUnmarshal is like Unmarshal but panics if the value cannot be
+// unmarshalled; it simplifies the initialisation of package-level variables
+// holding known-good data, e.g. embedded default configurations.
+func MustUnmarshal(value string, opts ...Option) interface{} {
+	result, err := Unmarshal(value, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("rawdata: Unmarshal(%q): %v", value, err))
 	}
+	return result
 }
 
+// MustUnmarshalInto is like UnmarshalInto but panics if the value cannot be
+// unmarshalled.
+func MustUnmarshalInto(value string, target interface{}, opts ...Option) {
+	if err := UnmarshalInto(value, target, opts...); err != nil {
+		panic(fmt.Sprintf("rawdata: UnmarshalInto(%q): %v", value, err))
+	}
+}
+
+// stdin is the reader used when the input value is "@-"; it is a variable so
+// that it can be replaced in tests.
+var stdin io.Reader = os.Stdin
+
 // ReadContent reads the data from the given input value,either taken as the
-// literal value to be parsed or as a path to a file (in either JSON or YAML
+// literal value to be parsed or as a path to a file (in JSON, YAML or TOML
 // format); it returns the auto-detected data format and the data itself as a
-// byte slice.
-func ReadContent(value string) (Format, []byte, error) {
-	var format Format
+// byte slice. The special value "@-" reads the data from standard input; since
+// there is no file extension to go by, its format is detected from the data
+// just like for inline values. Values starting with "http://" or "https://"
+// are fetched from the remote server (see HTTPClient), "file://" URIs are the
+// same as file references, URIs with registered schemes are loaded by their
+// loaders (see RegisterScheme), while "data:" URIs
+// (e.g. "data:application/json;base64,eyJhIjogMX0=") carry the data in the
+// value itself, with the format taken from the MIME type. File references can
+// be glob patterns (e.g. "@rules/*.yaml"), see loadGlob, directories whose
+// files are merged (e.g. "@conf.d/"), see WithMergeDirectory, or refer to a
+// member of a zip or tar archive (e.g. "@bundle.zip//config/app.yaml"), see
+// loadArchiveMember; "@mem:" references read in-memory sources instead (see
+// RegisterSource). A plain file reference is resolved in this order: a file
+// with exactly the given name is always used; otherwise, if the name refers
+// to a directory next to which there are files whose names only differ in
+// case (e.g. "Config" and "config/"), the reference is ambiguous and the
+// error (ErrAmbiguousFile) names all the candidates; otherwise a directory is
+// merged (see WithMergeDirectory) or rejected (ErrIsDirectory). Any of the
+// above can be prefixed with "json:", "yaml:" (or "yml:") or "toml:" to force
+// the format instead of detecting it, e.g. "json:[1, 2, 3]" or
+// "yaml:@myfile.conf".
+// Inline data that starts with "@" must be escaped as "\@" or "@@" (e.g.
+// "properties:@@user = alice"), otherwise it is taken as a file reference; the
+// escape is removed and the rest of the value is treated as inline data.
+func ReadContent(value string, opts ...Option) (Format, []byte, error) {
+	return readContent(value, newOptions(opts...))
+}
+
+// readContent is the implementation of ReadContent: it loads the data and
+// then applies any processing required by the options before returning it.
+func readContent(value string, o *options) (Format, []byte, error) {
+	format, content, err := loadContent(value, o)
+	if err != nil {
+		return format, nil, err
+	}
+	if o.contentTransform != nil {
+		if content, err = o.contentTransform(format, content); err != nil {
+			return format, nil, fmt.Errorf("error transforming %s: %w", describeValue(value), err)
+		}
+	}
+	if o.expandEnv {
+		content = expandEnv(content)
+	}
+	return format, content, nil
+}
+
+// loadContent loads the data from standard input, a file, a remote URL or
+// the inline value itself, and detects its format; an explicit format prefix
+// (e.g. "json:") overrides the auto-detection.
+func loadContent(value string, o *options) (Format, []byte, error) {
+	if err := o.context().Err(); err != nil {
+		return FormatUnknown, nil, err
+	}
+	format, value := cutFormatPrefix(value)
+	// the paths in "file://" URIs are decoded already
+	encoded := o.decodePath && !isFileURI(value)
+	if isFileURI(value) {
+		// it's the same as a file reference, with the same restrictions
+		filename, err := fileURIPath(value)
+		if err != nil {
+			return FormatUnknown, nil, err
+		}
+		value = "@" + filename
+	}
+	literal, escaped := cutEscape(value)
+	if o.requireFile && (escaped || value == "@-" || !strings.HasPrefix(value, "@")) {
+		if escaped {
+			return FormatUnknown, nil, fmt.Errorf("cannot read inline value: %w", ErrInlineNotAllowed)
+		}
+		return FormatUnknown, nil, fmt.Errorf("cannot read %s: %w", describeSource(value), ErrInlineNotAllowed)
+	}
+	if escaped {
+		// escaped inline data, never a file reference
+		return loadInline(format, literal, o)
+	}
+	if encoded && strings.HasPrefix(value, "@") && value != "@-" && !isSource(value) {
+		// only the path is decoded, never inline data
+		filename, err := url.PathUnescape(strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return FormatUnknown, nil, fmt.Errorf("invalid percent-encoded path '%s': %w", strings.TrimPrefix(value, "@"), err)
+		}
+		value = "@" + filename
+	}
+	if strings.HasPrefix(value, "@") && !isSource(value) && !o.allowFileAccess {
+		return FormatUnknown, nil, fmt.Errorf("cannot read '%s': %w", value, ErrFileAccessDisabled)
+	}
+	var detected Format
 	var content []byte
-	if strings.HasPrefix(value, "@") {
-		// it's a file on disk, check it exist
-		filename := strings.TrimPrefix(value, "@")
-		info, err := os.Stat(filename)
-		if os.IsNotExist(err) {
-			return format, nil, fmt.Errorf("file '%s' does not exist: %w", filename, err)
-		}
-		if info.IsDir() {
-			return format, nil, fmt.Errorf("'%s' is a directory, not a file", filename)
-		}
-		// read into memory
-		content, err = ioutil.ReadFile(filename)
+	var err error
+	if value == "@-" {
+		// it's standard input, read it all up to EOF
+		content, err = readAll(contextReader{o.context(), stdin}, o.maxFileSize)
 		if err != nil {
-			return format, nil, fmt.Errorf("error reading file '%s': %w", filename, err)
-		}
-		// type detection is based on file extension
-		ext := path.Ext(filename)
-		switch strings.ToLower(ext) {
-		case ".yaml", ".yml":
-			format = FormatYAML
-		case ".json":
-			format = FormatJSON
-		default:
-			return format, nil, fmt.Errorf("unsupported data format in file: %s", path.Ext(filename))
+			return FormatUnknown, nil, fmt.Errorf("error reading from standard input: %w", err)
+		}
+	} else if isSource(value) {
+		// it's an in-memory source
+		if detected, content, err = loadSource(value, o); err != nil {
+			return FormatUnknown, nil, err
+		}
+	} else if archive, member, ok := cutArchiveMember(value); ok {
+		// it's a member of an archive on disk
+		filename := resolvePath(archive, o)
+		detected, content, err = interruptible(filename, o, func() (Format, []byte, error) {
+			return loadArchiveMember(filename, member, format, o)
+		})
+		if err != nil {
+			return FormatUnknown, nil, err
+		}
+	} else if strings.HasPrefix(value, "@") {
+		// it's a file on disk (or a glob pattern matching several files)
+		filename := resolvePath(strings.TrimPrefix(value, "@"), o)
+		if isGlob(filename, o) {
+			detected, content, err = loadGlob(filename, format, o)
+		} else if o.mergeDirectory && isDirectory(filename, o) {
+			detected, content, err = loadDirectory(filename, format, o)
+		} else {
+			detected, content, err = loadFile(filename, format, o)
+		}
+		if err != nil {
+			return FormatUnknown, nil, err
+		}
+	} else if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		// it's a remote document, fetch it
+		if !o.remoteAccess() {
+			return FormatUnknown, nil, fmt.Errorf("cannot read '%s': %w", value, ErrRemoteAccessDisabled)
+		}
+		if detected, content, err = fetchContent(value, o); err != nil {
+			return FormatUnknown, nil, err
+		}
+	} else if loader, ok := registeredScheme(value); ok {
+		// it's a URI with a registered scheme, let its loader fetch it
+		if !o.remoteAccess() {
+			return FormatUnknown, nil, fmt.Errorf("cannot read '%s': %w", value, ErrRemoteAccessDisabled)
+		}
+		if detected, content, err = loadScheme(loader, value, o); err != nil {
+			return FormatUnknown, nil, err
+		}
+	} else if isDataURI(value) {
+		// it's a data URI, the payload is in the value itself
+		if detected, content, err = parseDataURI(value, o); err != nil {
+			return FormatUnknown, nil, err
 		}
 	} else {
 		// not a file, type detection is based on the data
-		value = strings.TrimSpace(value)
-		content = []byte(value)
-		if strings.HasPrefix(value, "---") {
-			format = FormatYAML
-		} else if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
-			// TODO: we could optimise by recording whether it's a struct or an array
-			format = FormatJSON
-		} else {
-			return format, nil, fmt.Errorf("unrecognisable input format in inline data")
+		return loadInline(format, value, o)
+	}
+	return detectFormat(format, detected, content, value, o)
+}
+
+// loadInline returns the inline value as data, and detects its format unless
+// it is given.
+func loadInline(format Format, value string, o *options) (Format, []byte, error) {
+	return detectFormat(format, FormatUnknown, []byte(strings.TrimSpace(value)), value, o)
+}
+
+// cutEscape checks whether the value starts with an escaped "@" ("\@" or
+// "@@"); if so, it returns the value without the escape character.
+func cutEscape(value string) (string, bool) {
+	if strings.HasPrefix(value, `\@`) || strings.HasPrefix(value, "@@") {
+		return value[1:], true
+	}
+	return value, false
+}
+
+// detectFormat prepares the loaded content for decoding and detects its
+// format, unless it is forced by a prefix or was detected while loading the
+// data (e.g. from the file extension); value is the input value, used in error
+// messages.
+func detectFormat(format Format, detected Format, content []byte, value string, o *options) (Format, []byte, error) {
+	var err error
+	// a leading byte order mark would break both detection and decoding
+	if content, err = decodeText(content); err != nil {
+		return FormatUnknown, nil, err
+	}
+	if o.template {
+		if content, err = renderTemplate(describeSource(value), content, o); err != nil {
+			return FormatUnknown, nil, err
+		}
+	}
+	if format == FormatUnknown {
+		format = detected
+		if o.verifyFormat {
+			if err = verifyFormat(format, content, o); err != nil {
+				return FormatUnknown, nil, fmt.Errorf("invalid data in %s: %w", describeSource(value), err)
+			}
+		}
+	}
+	if isEmpty(content) {
+		// there is nothing to detect the format from, or to decode
+		if !o.allowEmpty {
+			return FormatUnknown, nil, fmt.Errorf("no data in %s: %w", describeSource(value), ErrEmptyContent)
+		}
+		return format, content, nil
+	}
+	if err = checkEncoding(format, content); err != nil {
+		return FormatUnknown, nil, fmt.Errorf("invalid data in %s: %w", describeSource(value), err)
+	}
+	if format == FormatUnknown {
+		if format, err = sniffFormat(content, o); err != nil && o.autoDetect {
+			format, err = autoDetectFormat(content, o)
+		}
+		if err != nil {
+			return FormatUnknown, nil, err
 		}
 	}
 	return format, content, nil
 }
 
+// isEmpty returns whether the content is empty or only contains whitespace.
+func isEmpty(content []byte) bool {
+	return len(bytes.TrimSpace(content)) == 0
+}
+
+// describeSource returns a description of where the data for the given value
+// comes from, for use in error messages.
+func describeSource(value string) string {
+	switch {
+	case value == "@-":
+		return "standard input"
+	case strings.HasPrefix(value, "@"):
+		return fmt.Sprintf("'%s'", strings.TrimPrefix(value, "@"))
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return fmt.Sprintf("'%s'", value)
+	case isDataURI(value):
+		return "data URI"
+	default:
+		if _, ok := registeredScheme(value); ok {
+			return fmt.Sprintf("'%s'", value)
+		}
+		return "inline value"
+	}
+}
+
+// describeValue is like describeSource, but for values that may still have
+// a format prefix, an escape or a "file://" scheme.
+func describeValue(value string) string {
+	_, value = cutFormatPrefix(value)
+	if _, ok := cutEscape(value); ok {
+		return "inline value"
+	}
+	if isFileURI(value) {
+		if filename, err := fileURIPath(value); err == nil {
+			value = "@" + filename
+		}
+	}
+	return describeSource(value)
+}
+
+// cutFormatPrefix checks whether the value starts with an explicit format
+// prefix ("json:", "yaml:", "yml:" or "toml:"); if so, it returns the
+// corresponding format and the value without the prefix, otherwise it
+// returns FormatUnknown and the value unchanged. The prefix can be used both
+// with inline values (e.g. "toml:key = 'value'") and with file references
+// (e.g. "json:@myfile.conf").
+func cutFormatPrefix(value string) (Format, string) {
+	for prefix, format := range map[string]Format{
+		"json:":       FormatJSON,
+		"yaml:":       FormatYAML,
+		"yml:":        FormatYAML,
+		"toml:":       FormatTOML,
+		"csv:":        FormatCSV,
+		"properties:": FormatProperties,
+		"ini:":        FormatProperties,
+		"xml:":        FormatXML,
+		"ndjson:":     FormatNDJSON,
+		"jsonl:":      FormatNDJSON,
+	} {
+		if strings.HasPrefix(value, prefix) {
+			return format, strings.TrimPrefix(value, prefix)
+		}
+	}
+	return FormatUnknown, value
+}
+
+// resolvePath returns the path of a referenced file, with a leading "~"
+// expanded to the user's home directory and joined with the base directory if
+// one is set and the path is relative; when a custom filesystem is used, the
+// path is also converted to the format fs.FS expects.
+func resolvePath(filename string, o *options) string {
+	if o.fsys == nil {
+		filename = expandHome(filename)
+	}
+	if o.baseDir != "" && !filepath.IsAbs(filename) {
+		filename = filepath.Join(o.baseDir, filename)
+	}
+	if o.fsys != nil {
+		filename = strings.TrimPrefix(path.Clean(filepath.ToSlash(filename)), "/")
+	}
+	return filename
+}
+
+// expandHome replaces a leading "~" path segment (i.e. "~" or "~/...") with
+// the user's home directory; other uses of the tilde are left alone, and so
+// is the path if the home directory cannot be determined.
+func expandHome(filename string) string {
+	if filename != "~" && !strings.HasPrefix(filename, "~/") && !strings.HasPrefix(filename, "~"+string(filepath.Separator)) {
+		return filename
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filename
+	}
+	return filepath.Join(home, filename[1:])
+}
+
+// loadFile reads the given file into memory and detects its format from
+// the file extension; for compressed files, detection is based on the inner
+// extension (e.g. ".yaml" in "x.yaml.gz") and if there is none FormatUnknown
+// is returned, so that the caller can detect it from the data. Unless the
+// format is forced, an unsupported extension is an error.
+func loadFile(filename string, forced Format, o *options) (Format, []byte, error) {
+	_, content, err := interruptible(filename, o, func() (Format, []byte, error) {
+		content, err := readFileCached(filename, o)
+		return FormatUnknown, content, err
+	})
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	if ext := path.Ext(filename); strings.EqualFold(ext, ".gz") {
+		// the format of compressed data is detected from the data if the
+		// inner extension is not conclusive
+		return formatFromExtension(path.Ext(strings.TrimSuffix(filename, ext)), o), content, nil
+	}
+	format, err := fileFormat(filename, content, forced, o)
+	if err != nil {
+		return FormatUnknown, nil, err
+	}
+	return format, content, nil
+}
+
+// fileFormat returns the format of the file with the given name and content,
+// from its extension or, if that is not conclusive and it is allowed (see
+// WithSniffUnknownExtensions), from its content; not being able to detect it
+// is only an error if the format is not forced by a prefix.
+func fileFormat(filename string, content []byte, forced Format, o *options) (Format, error) {
+	ext := path.Ext(filename)
+	format := formatFromExtension(ext, o)
+	if format != FormatUnknown || forced != FormatUnknown {
+		return format, nil
+	}
+	if !o.sniffUnknownExtensions {
+		return FormatUnknown, fmt.Errorf("%w in file: %s", ErrUnsupportedFormat, ext)
+	}
+	if format = guessFormat(content, o); format == FormatUnknown {
+		return FormatUnknown, fmt.Errorf("%w in file '%s': not detectable from its contents", ErrUnsupportedFormat, filename)
+	}
+	return format, nil
+}
+
+// readFile reads the given file into memory, transparently decompressing it
+// if it has a ".gz" extension; the maximum file size, if set, applies both to
+// the file on disk and to the decompressed data.
+func readFile(filename string, o *options) ([]byte, error) {
+	file, info, err := openFile(filename, o)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if o.maxFileSize > 0 && info.Size() > o.maxFileSize {
+		return nil, fmt.Errorf("file '%s' is %d bytes, limit is %d: %w", filename, info.Size(), o.maxFileSize, ErrFileTooLarge)
+	}
+	var reader io.Reader = file
+	if strings.EqualFold(path.Ext(filename), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing file '%s': %w", filename, err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	content, err := readAll(contextReader{o.context(), reader}, o.maxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %w", filename, err)
+	}
+	if content, err = decodeText(content); err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %w", filename, err)
+	}
+	return content, nil
+}
+
+// openFile opens the given file for reading, after checking that it is
+// allowed by the options and that it is not a directory.
+func openFile(filename string, o *options) (fs.File, fs.FileInfo, error) {
+	if err := checkAllowedRoot(filename, o); err != nil {
+		return nil, nil, err
+	}
+	if err := checkSymlink(filename, o); err != nil {
+		return nil, nil, err
+	}
+	// check the file exists
+	info, err := fs.Stat(o.filesystem(), filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("file '%s' does not exist: %w", filename, ErrFileNotFound)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error accessing file '%s': %w", filename, err)
+	}
+	if info.IsDir() {
+		if err := checkAmbiguous(filename, o); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("'%s' %w", filename, ErrIsDirectory)
+	}
+	file, err := o.filesystem().Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file '%s': %w", filename, err)
+	}
+	return file, info, nil
+}
+
+// readAll reads all the data from the given reader up to EOF; if limit is
+// greater than 0, reading more than limit bytes is an error.
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("data exceeds the limit of %d bytes: %w", limit, ErrFileTooLarge)
+	}
+	return data, nil
+}
+
+// formatFromExtension returns the format associated with the given file
+// extension (including the leading dot), or FormatUnknown if the extension
+// is not supported; custom mappings (see WithExtensionMap) take precedence
+// over registered formats (see RegisterFormat), which in turn take precedence
+// over the built-in ones.
+func formatFromExtension(ext string, o *options) Format {
+	ext = strings.ToLower(ext)
+	if format, ok := o.extensions[ext]; ok {
+		return format
+	}
+	if format, ok := registeredFormat(ext); ok {
+		return format
+	}
+	switch ext {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json", ".jsonc":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	case ".csv":
+		return FormatCSV
+	case ".properties", ".ini":
+		return FormatProperties
+	case ".xml":
+		return FormatXML
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	default:
+		return FormatUnknown
+	}
+}
+
+// sniffFormat detects the format of data that does not come with a file
+// extension (inline values, standard input) by looking at its first
+// characters: YAML MUST start with '---', JSON with either '{' or '[', XML
+// with '<'; if scalars are allowed, anything else that is a valid YAML scalar
+// (e.g. 42, true or hello) is treated as YAML.
+func sniffFormat(content []byte, o *options) (Format, error) {
+	if o.allowComments {
+		content = stripJSONComments(content)
+	}
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("---")) {
+		return FormatYAML, nil
+	} else if bytes.HasPrefix(content, []byte("{")) || bytes.HasPrefix(content, []byte("[")) {
+		// the shape is recovered from the same character (see jsonShape)
+		return FormatJSON, nil
+	} else if bytes.HasPrefix(content, []byte("<")) {
+		return FormatXML, nil
+	} else if isYAMLScalar(content) {
+		if o.allowScalars {
+			return FormatYAML, nil
+		}
+	} else if o.implicitYAML {
+		// malformed YAML is reported by the YAML decoder
+		return FormatYAML, nil
+	}
+	return FormatUnknown, ErrUnrecognisedInline
+}
+
+// isYAMLScalar returns whether the content is a YAML document consisting of
+// a single scalar value.
+func isYAMLScalar(content []byte) bool {
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(content, &document); err != nil || len(document.Content) == 0 {
+		return false
+	}
+	return document.Content[0].Kind == yaml.ScalarNode
+}
+
 // unmarshalJSON unmarshals a JSON document; a JSON document can
 // represent either an object or an array but the standard library
 // methods expect the target object to be pre-allocated; thus, we
-// try to unmarshal to a map, which is the most general representation
-// of a struct; if it fails with a parse error because the JSON document
-// represents an array, we try with an array next.
-func unmarshalJSON(content []byte) (interface{}, error) {
-	// first attempt: unmarshalling to a map (like a struct would)...
-	m := map[string]interface{}{}
-	if err := json.Unmarshal(content, &m); err != nil {
-		if err, ok := err.(*json.UnmarshalTypeError); ok {
-			if err.Value == "array" && err.Offset == 1 {
-				// second attempt: it is not a struct, it's an array, let's try that...
-				a := []interface{}{}
-				if err := json.Unmarshal(content, &a); err != nil {
-					return nil, fmt.Errorf("error unmarshalling from JSON: %w", err)
-				}
-				return a, nil
-			}
+// peek at the first non-whitespace character to find out whether the
+// document is an object ('{') or an array ('['), and unmarshal it into
+// a map or an array accordingly, in a single pass.
+func unmarshalJSON(content []byte, o *options) (interface{}, error) {
+	var result interface{}
+	switch jsonShape(content) {
+	case ShapeObject:
+		m := map[string]interface{}{}
+		if err := jsonUnmarshal(content, &m, o); err != nil {
+			return nil, newDecodeError(FormatJSON, content, err)
+		}
+		result = m
+	case ShapeArray:
+		a := []interface{}{}
+		if err := jsonUnmarshal(content, &a, o); err != nil {
+			return nil, newDecodeError(FormatJSON, content, err)
+		}
+		result = a
+	default:
+		if err := jsonUnmarshal(content, &result, o); err != nil {
+			return nil, newDecodeError(FormatJSON, content, err)
 		}
-		return nil, fmt.Errorf("error unmarshalling from JSON: %w", err)
 	}
-	return m, nil
+	return result, nil
+}
+
+// jsonUnmarshal is like json.Unmarshal, but it decodes numbers as
+// json.Number if the options say so.
+func jsonUnmarshal(content []byte, target interface{}, o *options) error {
+	if !o.useNumber {
+		return json.Unmarshal(content, target)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(target); err != nil {
+		return err
+	}
+	// like json.Unmarshal, reject trailing data after the document
+	if _, err := decoder.Token(); err != io.EOF {
+		return errTrailingData
+	}
+	return nil
+}
+
+// firstByte returns the first non-whitespace byte in the content, or 0 if
+// there is none.
+func firstByte(content []byte) byte {
+	if content = bytes.TrimSpace(content); len(content) > 0 {
+		return content[0]
+	}
+	return 0
 }
 
 // unmarshalYAML unmarshals a YAML document; a YAML document can
-// represent either an object or an array but the YAML library
-// methods expect the target object to be pre-allocated; thus, we
-// try to unmarshal to a map, which is the most general representation
-// of a struct; if it fails with a parse error because the YAML document
-// represents an array, we try with an array next.
-func unmarshalYAML(content []byte) (interface{}, error) {
-	object := map[string]interface{}{}
-	if err := yaml.Unmarshal(content, object); err != nil {
-		if err, ok := err.(*yaml.TypeError); ok {
-			// TODO: find a way to circumvent marshalling error in case of array
-			for _, e := range err.Errors {
-				if strings.HasSuffix(e, "cannot unmarshal !!seq into map[string]interface {}") {
-					// second attempt: it is not a struct, it's an array, let's try that...
-					a := []interface{}{}
-					if err := yaml.Unmarshal(content, &a); err != nil {
-						return nil, fmt.Errorf("error unmarshalling from YAML: %w", err)
-					}
-					return a, nil
-				}
-			}
-			return nil, fmt.Errorf("error: %s, %+v", err.Error(), err.Errors)
+// represent either an object, an array or a scalar but the YAML library
+// methods expect the target object to be pre-allocated; thus, we first
+// decode the document into a node tree, inspect the kind of its root
+// node and then decode it into a map, an array or a generic value
+// accordingly.
+func unmarshalYAML(content []byte, o *options) (interface{}, error) {
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, newDecodeError(FormatYAML, content, err)
+	}
+	return decodeYAMLDocument(&document, o)
+}
+
+// decodeYAMLDocument decodes a YAML document node into a map, an array or
+// a generic value, depending on the kind of its root node; if the options
+// require special handling of maps or numbers, the node tree is walked
+// and converted explicitly.
+func decodeYAMLDocument(document *yaml.Node, o *options) (interface{}, error) {
+	if len(document.Content) == 0 {
+		// empty document (e.g. only comments)
+		return nil, nil
+	}
+	if err := checkYAMLLimits(document, o); err != nil {
+		return nil, err
+	}
+	if o.yamlBoolCompat {
+		convertYAMLBools(document, map[*yaml.Node]bool{})
+	}
+	root := document.Content[0]
+	if o.orderedMaps || o.useNumber || o.yamlMergeKeys {
+		result, err := decodeYAMLNode(root, o)
+		if err != nil {
+			return nil, newDecodeError(FormatYAML, nil, err)
+		}
+		return result, nil
+	}
+	kind := root.Kind
+	if kind == yaml.AliasNode {
+		kind = root.Alias.Kind
+	}
+	switch kind {
+	case yaml.MappingNode:
+		object := map[string]interface{}{}
+		if err := root.Decode(&object); err != nil {
+			return nil, newDecodeError(FormatYAML, nil, err)
+		}
+		return object, nil
+	case yaml.SequenceNode:
+		array := []interface{}{}
+		if err := root.Decode(&array); err != nil {
+			return nil, newDecodeError(FormatYAML, nil, err)
 		}
-		return nil, fmt.Errorf("error unmarshalling from YAML: %w (%T)", err, err)
+		return array, nil
+	default:
+		var scalar interface{}
+		if err := root.Decode(&scalar); err != nil {
+			return nil, newDecodeError(FormatYAML, nil, err)
+		}
+		return scalar, nil
+	}
+}
+
+// checkYAMLLimits returns an error if the YAML document is nested too deeply
+// (see WithMaxDepth) or its aliases expand to too many nodes (see
+// WithMaxAliasExpansion).
+func checkYAMLLimits(document *yaml.Node, o *options) error {
+	if err := checkYAMLDepth(document, o); err != nil {
+		return err
+	}
+	return checkYAMLAliases(document, o)
+}
+
+// unmarshalTOML unmarshals a TOML document; unlike JSON and YAML, a
+// TOML document always represents a table at the top level (there is
+// no such thing as a top-level array), so there is no need for the
+// array fallback: the document is always unmarshalled into a map.
+func unmarshalTOML(content []byte, o *options) (interface{}, error) {
+	object := map[string]interface{}{}
+	metadata, err := toml.Decode(string(content), &object)
+	if err != nil {
+		return nil, newDecodeError(FormatTOML, content, err)
+	}
+	if o.orderedMaps || o.useNumber {
+		return convertTOML(object, metadata, o), nil
 	}
 	return object, nil
 }
@@ -1,6 +1,17 @@
 package rawdata
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
 
 type s struct {
 	Name    string `json:"name"`
@@ -9,7 +20,7 @@ type s struct {
 }
 
 func TestUnmarshalNonExistingFile(t *testing.T) {
-	for _, file := range []string{"@./test/nonexisting.json", "@./test/nonexisting.yaml", "@./test/test.toml", "@./test"} {
+	for _, file := range []string{"@./test/nonexisting.json", "@./test/nonexisting.yaml", "@./test/test.txt", "@./test"} {
 		_, err := Unmarshal(file)
 		if err == nil {
 			t.Fatal("no error on non-existing file")
@@ -56,7 +67,7 @@ age: 23
 }
 
 func TestUnmarshalIntoNonExistingFile(t *testing.T) {
-	for _, file := range []string{"@./test/nonexisting.json", "@./test/nonexisting.yaml", "@./test/test.toml"} {
+	for _, file := range []string{"@./test/nonexisting.json", "@./test/nonexisting.yaml", "@./test/test.txt"} {
 		result := &s{}
 		err := UnmarshalInto(file, result)
 		if err == nil {
@@ -430,3 +441,479 @@ func TestUnmarshalIntoArrayFromYAMLInline(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalStructFromTOMLFile(t *testing.T) {
+	input := "@./test/struct.toml"
+	result, err := Unmarshal(input)
+	if err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+	if result, ok := result.(map[string]interface{}); !ok {
+		t.Fatalf("invalid output type: %T", result)
+	} else {
+
+		for k, v := range map[string]interface{}{
+			"name":    "John",
+			"surname": "Doe",
+			"age":     int64(23),
+		} {
+			if result[k] != v {
+				t.Errorf("error unmarshalling from file: expected %v (type %T) for key %v, got %v (type %T)", v, v, k, result[k], result[k])
+			}
+		}
+	}
+}
+
+func TestUnmarshalIntoStructFromTOMLFile(t *testing.T) {
+	input := "@./test/struct.toml"
+	result := &s{}
+	err := UnmarshalInto(input, result)
+	if err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+	if result.Name != "John" {
+		t.Fatalf("invalid value for name: expected John, got %v", result.Name)
+	}
+	if result.Surname != "Doe" {
+		t.Fatalf("invalid value for name: expected Doe, got %v", result.Surname)
+	}
+	if result.Age != 23 {
+		t.Fatalf("invalid value for name: expected 123, got %v", result.Age)
+	}
+}
+
+func TestUnmarshalFromStdin(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+
+	stdin = strings.NewReader("---\n- one\n- two\n- three\n")
+	result, err := Unmarshal("@-")
+	if err != nil {
+		t.Fatalf("error unmarshalling from stdin: %v", err)
+	}
+	if result, ok := result.([]interface{}); !ok {
+		t.Fatalf("invalid output type: %T", result)
+	} else {
+		for i, v := range []interface{}{"one", "two", "three"} {
+			if result[i] != v {
+				t.Errorf("error unmarshalling from stdin: expected %v (type %T) for index %d, got %v (type %T)", v, v, i, result[i], result[i])
+			}
+		}
+	}
+
+	stdin = strings.NewReader(`{"name": "John", "surname": "Doe", "age": 23}`)
+	target := &s{}
+	if err := UnmarshalInto("@-", target); err != nil {
+		t.Fatalf("error unmarshalling from stdin: %v", err)
+	}
+	if target.Name != "John" || target.Surname != "Doe" || target.Age != 23 {
+		t.Fatalf("invalid value unmarshalled from stdin: %+v", target)
+	}
+
+	stdin = strings.NewReader(" \n\t")
+	if _, err := Unmarshal("@-"); !errors.Is(err, ErrEmptyContent) {
+		t.Fatal("no error on empty stdin")
+	}
+}
+
+func TestUnmarshalArrayWithLeadingWhitespace(t *testing.T) {
+	defer func(r io.Reader) { stdin = r }(stdin)
+
+	expected := []interface{}{"one", "two", "three"}
+	for _, content := range []string{
+		`   ["one", "two", "three"]`,
+		"\n\n\t[\"one\", \"two\", \"three\"]\n",
+		"\r\n  [\r\n    \"one\",\r\n    \"two\",\r\n    \"three\"\r\n  ]\r\n",
+	} {
+		result, err := unmarshalJSON([]byte(content), newOptions())
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", content, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for %q: %v (type %T)", content, result, result)
+		}
+		stdin = strings.NewReader(content)
+		if result, err = Unmarshal("@-"); err != nil || !reflect.DeepEqual(result, expected) {
+			t.Errorf("invalid result for %q from stdin: %v (error: %v)", content, result, err)
+		}
+	}
+	result, err := Unmarshal("@./test/indented-array.json")
+	if err != nil || !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result from file: %v (error: %v)", result, err)
+	}
+	target := []string{}
+	if err := UnmarshalInto("@./test/indented-array.json", &target); err != nil || len(target) != 3 {
+		t.Errorf("invalid target from file: %v (error: %v)", target, err)
+	}
+}
+
+func TestUnmarshalTyped(t *testing.T) {
+	for _, input := range []string{`{"name": "John", "surname": "Doe", "age": 23}`, "@./test/struct.yaml"} {
+		result, err := UnmarshalTyped[s](input)
+		if err != nil {
+			t.Fatalf("error unmarshalling: %v", err)
+		}
+		if result.Name != "John" || result.Surname != "Doe" || result.Age != 23 {
+			t.Fatalf("invalid value unmarshalled: %+v", result)
+		}
+	}
+	for _, input := range []string{`["one", "two", "three"]`, "@./test/array.yaml"} {
+		result, err := UnmarshalTyped[[]string](input)
+		if err != nil {
+			t.Fatalf("error unmarshalling: %v", err)
+		}
+		for i, v := range []string{"one", "two", "three"} {
+			if result[i] != v {
+				t.Errorf("error unmarshalling: expected %v for index %d, got %v", v, i, result[i])
+			}
+		}
+	}
+	if result, err := UnmarshalTyped[s]("@./test/invalid.json"); err == nil {
+		t.Fatal("no error on invalid file")
+	} else if result != (s{}) {
+		t.Fatalf("expected zero value on error, got %+v", result)
+	}
+}
+
+func TestUnmarshalWithFormat(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"@./test/struct.json":      FormatJSON,
+		"@./test/array.yaml":       FormatYAML,
+		"@./test/struct.toml":      FormatTOML,
+		`["one", "two", "three"]`:  FormatJSON,
+		"---\nname: John\nage: 23": FormatYAML,
+	} {
+		result, format, err := UnmarshalWithFormat(input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if result == nil {
+			t.Fatalf("no result unmarshalling %q", input)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+	}
+}
+
+func TestUnmarshalIntoTrailingData(t *testing.T) {
+	for _, input := range []string{`{"name": "John"} {"name": "Jane"}`, `{"name": "John"}}`} {
+		if err := UnmarshalInto(input, &s{}); err == nil {
+			t.Fatalf("no error on trailing data in %q", input)
+		}
+	}
+}
+
+func TestUnmarshalWithFormatPrefix(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"json:[1, 2, 3]":                         FormatJSON,
+		"yaml:name: John\nsurname: Doe\nage: 23": FormatYAML,
+		"toml:name = 'John'\nage = 23":           FormatTOML,
+		"json:@./test/struct.conf":               FormatJSON,
+		"yaml:@./test/struct.json":               FormatYAML,
+	} {
+		result, format, err := UnmarshalWithFormat(input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if result == nil {
+			t.Fatalf("no result unmarshalling %q", input)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+	}
+	if _, err := Unmarshal("@./test/struct.conf"); err == nil {
+		t.Fatal("no error on unsupported file extension without format prefix")
+	}
+}
+
+func TestUnmarshalEscapedInline(t *testing.T) {
+	for _, input := range []string{"properties:@@user = alice", `properties:\@user = alice`} {
+		result, err := Unmarshal(input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if user := result.(map[string]interface{})["@user"]; user != "alice" {
+			t.Errorf("invalid value for %q: expected \"alice\", got %v", input, user)
+		}
+	}
+	format, content, err := ReadContent(`csv:\@handle,name`, WithAllowFileAccess(false))
+	if err != nil {
+		t.Fatalf("error reading escaped inline value: %v", err)
+	}
+	if format != FormatCSV || string(content) != "@handle,name" {
+		t.Errorf("invalid content for escaped inline value: %q", content)
+	}
+	if _, err := Unmarshal("@@./test/struct.json"); errors.Is(err, ErrFileNotFound) || err == nil {
+		t.Errorf("invalid error for escaped file reference: %v", err)
+	}
+}
+
+func TestUnmarshalFromGzippedFile(t *testing.T) {
+	result := &s{}
+	if err := UnmarshalInto("@./test/struct.yaml.gz", result); err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+	if result.Name != "John" || result.Surname != "Doe" || result.Age != 23 {
+		t.Fatalf("invalid value unmarshalled from file: %+v", result)
+	}
+
+	// no inner extension, the format is detected from the data
+	array, format, err := UnmarshalWithFormat("@./test/array.gz")
+	if err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+	if format != FormatJSON {
+		t.Fatalf("invalid format: expected %v, got %v", FormatJSON, format)
+	}
+	if array, ok := array.([]interface{}); !ok || len(array) != 3 {
+		t.Fatalf("invalid output: %v (type %T)", array, array)
+	}
+
+	// the size limit applies to the decompressed data
+	if _, err := Unmarshal("@./test/large.json.gz", WithMaxFileSize(1024)); err == nil {
+		t.Fatal("no error on decompressed data exceeding the maximum size")
+	}
+	if _, err := Unmarshal("@./test/large.json.gz"); err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+}
+
+func TestUnmarshalScalarFromYAMLInline(t *testing.T) {
+	for input, expected := range map[string]interface{}{
+		"--- 42":     42,
+		"--- hello":  "hello",
+		"---\ntrue":  true,
+		"---\n3.14":  3.14,
+		"--- ~":      nil,
+		"--- 'text'": "text",
+	} {
+		result, err := Unmarshal(input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if result != expected {
+			t.Errorf("error unmarshalling %q: expected %v (type %T), got %v (type %T)", input, expected, expected, result, result)
+		}
+	}
+}
+
+func TestUnmarshalScalarYAMLDocument(t *testing.T) {
+	fsys := fstest.MapFS{
+		"string.yaml":  &fstest.MapFile{Data: []byte("\"hello\"\n")},
+		"plain.yaml":   &fstest.MapFile{Data: []byte("hello world\n")},
+		"int.yaml":     &fstest.MapFile{Data: []byte("42\n")},
+		"float.yaml":   &fstest.MapFile{Data: []byte("# a comment\n-1.5e3\n")},
+		"bool.yaml":    &fstest.MapFile{Data: []byte("false\n")},
+		"null.yaml":    &fstest.MapFile{Data: []byte("null\n")},
+		"explicit.yml": &fstest.MapFile{Data: []byte("--- !!str 123\n")},
+	}
+	for filename, expected := range map[string]interface{}{
+		"string.yaml":  "hello",
+		"plain.yaml":   "hello world",
+		"int.yaml":     42,
+		"float.yaml":   -1500.0,
+		"bool.yaml":    false,
+		"null.yaml":    nil,
+		"explicit.yml": "123",
+	} {
+		result, err := Unmarshal("@"+filename, WithFS(fsys))
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", filename, err)
+		}
+		if result != expected {
+			t.Errorf("invalid result for %q: expected %v (type %T), got %v (type %T)", filename, expected, expected, result, result)
+		}
+		var target interface{}
+		if err := UnmarshalInto("@"+filename, &target, WithFS(fsys)); err != nil || target != expected {
+			t.Errorf("invalid target for %q: %v (error: %v)", filename, target, err)
+		}
+	}
+	number := 0
+	if err := UnmarshalInto("@int.yaml", &number, WithFS(fsys)); err != nil || number != 42 {
+		t.Errorf("invalid typed target: %v (error: %v)", number, err)
+	}
+}
+
+func TestUnmarshalNestedStructFromYAMLFile(t *testing.T) {
+	result, err := Unmarshal("@./test/nested.yaml")
+	if err != nil {
+		t.Fatalf("error unmarshalling from file: %v", err)
+	}
+	expected := map[string]interface{}{
+		"name": "John",
+		"address": map[string]interface{}{
+			"street": "Baker Street",
+			"number": 221,
+			"city": map[string]interface{}{
+				"name":    "London",
+				"country": "UK",
+			},
+		},
+		"tags": []interface{}{"one", "two"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("error unmarshalling from file: expected %v, got %v", expected, result)
+	}
+}
+
+func BenchmarkUnmarshalArrayFromJSONInline(b *testing.B) {
+	items := make([]string, 10000)
+	for i := range items {
+		items[i] = `{"name": "John", "surname": "Doe", "age": 23}`
+	}
+	input := "[" + strings.Join(items, ",") + "]"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Unmarshal(input); err != nil {
+			b.Fatalf("error unmarshalling: %v", err)
+		}
+	}
+}
+
+func TestFormatString(t *testing.T) {
+	for format, expected := range map[Format]string{
+		FormatUnknown: "unknown",
+		FormatJSON:    "json",
+		FormatYAML:    "yaml",
+		FormatTOML:    "toml",
+		Format(255):   "Format(255)",
+	} {
+		if actual := format.String(); actual != expected {
+			t.Errorf("invalid string for format %d: expected %q, got %q", uint8(format), expected, actual)
+		}
+	}
+	if actual := fmt.Sprintf("unsupported encoding: %v", FormatYAML); actual != "unsupported encoding: yaml" {
+		t.Errorf("invalid formatted string: %q", actual)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]Format{
+		"json":    FormatJSON,
+		"JSON":    FormatJSON,
+		"Yaml":    FormatYAML,
+		"yml":     FormatYAML,
+		"toml":    FormatTOML,
+		"unknown": FormatUnknown,
+	} {
+		format, err := ParseFormat(input)
+		if err != nil {
+			t.Fatalf("error parsing format %q: %v", input, err)
+		}
+		if format != expected {
+			t.Errorf("invalid format for %q: expected %v, got %v", input, expected, format)
+		}
+	}
+	if format, err := ParseFormat("hcl"); err == nil || format != FormatUnknown {
+		t.Errorf("invalid result parsing unknown format: %v (error: %v)", format, err)
+	}
+}
+
+func TestFormatTextMarshalling(t *testing.T) {
+	type config struct {
+		Format Format `json:"format" yaml:"format"`
+	}
+	data, err := json.Marshal(config{Format: FormatYAML})
+	if err != nil || string(data) != `{"format":"yaml"}` {
+		t.Fatalf("invalid JSON: %s (error: %v)", data, err)
+	}
+	for _, input := range []string{`{"format": "TOML"}`, "---\nformat: toml\n"} {
+		result, err := UnmarshalTyped[config](input)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", input, err)
+		}
+		if result.Format != FormatTOML {
+			t.Errorf("invalid format unmarshalled from %q: %v", input, result.Format)
+		}
+	}
+	if _, err := UnmarshalTyped[config](`{"format": "hcl"}`); err == nil {
+		t.Error("no error unmarshalling unknown format")
+	}
+}
+
+func TestUnmarshalEmptyContent(t *testing.T) {
+	for _, value := range []string{"@test/empty.json", "", "   ", " \n\t \n"} {
+		if _, err := Unmarshal(value); !errors.Is(err, ErrEmptyContent) {
+			t.Errorf("invalid error unmarshalling %q: %v", value, err)
+		}
+		result, err := Unmarshal(value, WithAllowEmpty(true))
+		if err != nil || result != nil {
+			t.Errorf("invalid result unmarshalling %q: %v (error: %v)", value, result, err)
+		}
+		target := s{Name: "John"}
+		if err := UnmarshalInto(value, &target, WithAllowEmpty(true)); err != nil || target.Name != "John" {
+			t.Errorf("invalid target unmarshalling %q: %+v (error: %v)", value, target, err)
+		}
+	}
+}
+
+func TestUnmarshalEmptyYAMLDocument(t *testing.T) {
+	result, err := Unmarshal("@test/comment.yaml")
+	if err != nil {
+		t.Fatalf("error unmarshalling empty YAML document: %v", err)
+	}
+	if result != nil {
+		t.Errorf("invalid result for empty YAML document: %v", result)
+	}
+}
+
+func TestMustUnmarshal(t *testing.T) {
+	result := MustUnmarshal(`{"name": "John"}`)
+	if !reflect.DeepEqual(result, map[string]interface{}{"name": "John"}) {
+		t.Errorf("invalid result: %v", result)
+	}
+	target := &s{}
+	MustUnmarshalInto("@./test/struct.yaml", target)
+	if target.Name != "John" || target.Age != 23 {
+		t.Errorf("invalid target: %+v", target)
+	}
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("no panic on invalid data")
+		}
+		if message := fmt.Sprint(r); !strings.Contains(message, "does not exist") {
+			t.Errorf("invalid panic message: %s", message)
+		}
+	}()
+	MustUnmarshal("@./test/missing.json")
+}
+
+func TestUnmarshalFromHomeDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	if err := os.WriteFile(filepath.Join(home, "app.json"), []byte(`{"name": "John"}`), 0644); err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+	result, err := Unmarshal("@~/app.json")
+	if err != nil {
+		t.Fatalf("error unmarshalling from home directory: %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]interface{}{"name": "John"}) {
+		t.Errorf("invalid result: %v", result)
+	}
+	// only a leading "~" segment is expanded
+	if _, err := Unmarshal("@~app.json"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for non-expanded tilde: %v", err)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	tests := map[string]string{
+		"~":               home,
+		"~/app.yaml":      filepath.Join(home, "app.yaml"),
+		"~user/app.yaml":  "~user/app.yaml",
+		"config/~/a.yaml": "config/~/a.yaml",
+		"backup~":         "backup~",
+	}
+	for input, expected := range tests {
+		if actual := expandHome(input); actual != expected {
+			t.Errorf("invalid expansion of %q: expected %q, got %q", input, expected, actual)
+		}
+	}
+}
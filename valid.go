@@ -0,0 +1,79 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Valid reads the data from the given value and detects its format, as
+// Unmarshal does, and then checks that the data is well-formed in that format
+// without building the values it represents: JSON (and each line of NDJSON)
+// is only scanned, and YAML is only parsed into a node tree, on which the
+// limits set via WithMaxDepth and WithMaxAliasExpansion are checked without
+// expanding any aliases. The other formats, which have no cheaper check, are
+// decoded in full. Valid returns the format of the data and nil if it is
+// well-formed, or else a DecodeError; YAML data that is syntactically valid
+// but cannot be decoded (e.g. because of duplicate keys) is still accepted.
+func Valid(value string, opts ...Option) (Format, error) {
+	o := newOptions(opts...)
+	format, content, err := readContent(value, o)
+	if err != nil {
+		return format, err
+	}
+	if err := valid(format, content, o); err != nil {
+		return format, fmt.Errorf("error decoding %s: %w", describeValue(value), err)
+	}
+	return format, nil
+}
+
+// valid checks that the content is well-formed in the given format.
+func valid(format Format, content []byte, o *options) error {
+	if isEmpty(content) && o.allowEmpty {
+		return nil
+	}
+	switch format {
+	case FormatJSON:
+		return validJSON(content, o)
+	case FormatNDJSON:
+		for number, line := range bytes.Split(content, []byte("\n")) {
+			if isEmpty(line) {
+				continue
+			}
+			if err := validJSON(line, o); err != nil {
+				if decodeError, ok := err.(*DecodeError); ok {
+					return &DecodeError{DataFormat: FormatNDJSON, Line: number + 1, Column: decodeError.Column, Err: decodeError.Err}
+				}
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		document := yaml.Node{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return newDecodeError(FormatYAML, content, err)
+		}
+		return checkYAMLLimits(&document, o)
+	default:
+		_, err := decode(format, content, o)
+		return err
+	}
+}
+
+// validJSON checks that the content is a single well-formed JSON value.
+func validJSON(content []byte, o *options) error {
+	content = prepareJSON(content, o)
+	if err := checkJSONDepth(content, o); err != nil {
+		return err
+	}
+	if json.Valid(content) {
+		return nil
+	}
+	// decode it, only to find out where the problem is
+	if err := json.Unmarshal(content, new(json.RawMessage)); err != nil {
+		return newDecodeError(FormatJSON, content, err)
+	}
+	return nil
+}
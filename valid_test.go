@@ -0,0 +1,53 @@
+package rawdata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for input, expected := range map[string]Format{
+		`{"a": [1, 2, {"b": null}]}`:     FormatJSON,
+		"---\na: [1, 2]\nb: &x {c: 1}\n": FormatYAML,
+		"@./test/struct.json":            FormatJSON,
+		"@./test/struct.yaml":            FormatYAML,
+		"toml:a = 1":                     FormatTOML,
+		"ndjson:{\"a\": 1}\n\n[2]\n":     FormatNDJSON,
+	} {
+		format, err := Valid(input)
+		if err != nil || format != expected {
+			t.Errorf("invalid result for %q: expected %v, got %v (%v)", input, expected, format, err)
+		}
+	}
+	tests := []struct {
+		input  string
+		format Format
+		line   int
+	}{
+		{"{\n  \"a\": [1, 2,]\n}", FormatJSON, 2},
+		{"json:{\"a\": 1} {\"b\": 2}", FormatJSON, 1},
+		{"---\nname: John\n  surname: Doe\n", FormatYAML, 3},
+		{"ndjson:{\"a\": 1}\n{\"a\": }\n", FormatNDJSON, 2},
+		{"toml:a = ", FormatTOML, 1},
+	}
+	for _, test := range tests {
+		format, err := Valid(test.input)
+		var decodeError *DecodeError
+		if !errors.As(err, &decodeError) || format != test.format || decodeError.Format() != test.format || decodeError.Line != test.line {
+			t.Errorf("invalid error for %q: %v (format %v)", test.input, err, format)
+		}
+	}
+	if _, err := Valid(`{"a": {"b": {"c": 1}}}`, WithMaxDepth(2)); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Errorf("invalid error for deep JSON: %v", err)
+	}
+	if _, err := Valid("---\na: &a [1, 2]\nb: *a\nc: *a\n", WithMaxAliasExpansion(5)); !errors.Is(err, ErrAliasBudgetExceeded) {
+		t.Errorf("invalid error for YAML aliases: %v", err)
+	}
+	if _, err := Valid(`json:// comment
+{"a": 1}`, WithAllowComments(true)); err != nil {
+		t.Errorf("invalid error for JSON with comments: %v", err)
+	}
+	if _, err := Valid("@./test/nonexisting.json"); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("invalid error for missing file: %v", err)
+	}
+}
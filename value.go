@@ -0,0 +1,51 @@
+package rawdata
+
+// IsObject returns whether the given value, as returned by Unmarshal, is an
+// object, i.e. a map[string]interface{} or a non-nil *OrderedMap.
+func IsObject(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return true
+	case *OrderedMap:
+		return v != nil
+	default:
+		return false
+	}
+}
+
+// IsArray returns whether the given value, as returned by Unmarshal, is an
+// array, i.e. an []interface{}.
+func IsArray(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+// AsObject returns the given value, as returned by Unmarshal, as a map, and
+// whether it is an object at all; ordered maps (see WithOrderedMaps) are
+// converted to a new plain map, whose values are shared with the original,
+// so that callers need not handle both types. Nil values are not objects.
+func AsObject(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case *OrderedMap:
+		if v == nil {
+			return nil, false
+		}
+		object := make(map[string]interface{}, v.Len())
+		v.Range(func(key string, value interface{}) bool {
+			object[key] = value
+			return true
+		})
+		return object, true
+	default:
+		return nil, false
+	}
+}
+
+// AsArray returns the given value, as returned by Unmarshal, as a slice, and
+// whether it is an array at all. Nil values are not arrays.
+func AsArray(v interface{}) ([]interface{}, bool) {
+	array, ok := v.([]interface{})
+	return array, ok
+}
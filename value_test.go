@@ -0,0 +1,54 @@
+package rawdata
+
+import (
+	"testing"
+)
+
+func TestObjectAndArrayHelpers(t *testing.T) {
+	var nilMap *OrderedMap
+	testCases := []struct {
+		value  string
+		opts   []Option
+		object bool
+		array  bool
+	}{
+		{value: `{"a": 1, "b": [2]}`, object: true},
+		{value: `{"a": 1, "b": [2]}`, opts: []Option{WithOrderedMaps(true)}, object: true},
+		{value: "---\n- a\n- b\n", array: true},
+		{value: `"text"`, opts: []Option{WithAllowScalars(true)}},
+	}
+	for _, test := range testCases {
+		result, err := Unmarshal(test.value, test.opts...)
+		if err != nil {
+			t.Fatalf("error unmarshalling %q: %v", test.value, err)
+		}
+		if IsObject(result) != test.object || IsArray(result) != test.array {
+			t.Errorf("invalid classification for %q: object %t, array %t", test.value, IsObject(result), IsArray(result))
+		}
+		object, ok := AsObject(result)
+		if ok != test.object {
+			t.Errorf("invalid object conversion for %q: %v", test.value, ok)
+		}
+		if ok && (len(object) != 2 || object["a"] == nil || !IsArray(object["b"])) {
+			t.Errorf("invalid object for %q: %v", test.value, object)
+		}
+		array, ok := AsArray(result)
+		if ok != test.array {
+			t.Errorf("invalid array conversion for %q: %v", test.value, ok)
+		}
+		if ok && len(array) != 2 {
+			t.Errorf("invalid array for %q: %v", test.value, array)
+		}
+	}
+	for _, v := range []interface{}{nil, nilMap} {
+		if IsObject(v) || IsArray(v) {
+			t.Errorf("nil value %#v classified as object or array", v)
+		}
+		if object, ok := AsObject(v); ok || object != nil {
+			t.Errorf("nil value %#v converted to object", v)
+		}
+		if array, ok := AsArray(v); ok || array != nil {
+			t.Errorf("nil value %#v converted to array", v)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package rawdata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// xmlAttributePrefix is prepended to the names of XML attributes to
+	// tell them apart from child elements with the same name.
+	xmlAttributePrefix = "-"
+	// xmlTextKey is the key under which the text of XML elements with
+	// attributes or child elements is stored.
+	xmlTextKey = "#text"
+)
+
+// unmarshalXML unmarshals an XML document into an object with a single key,
+// the name of the root element. Elements are mapped as follows:
+//   - an element with neither attributes nor child elements becomes a string
+//     holding its text, trimmed of leading and trailing whitespace;
+//   - any other element becomes an object, with a key for each attribute
+//     (its name prefixed by "-", e.g. "-id"), a key for each child element
+//     (whose value is an array if the child element is repeated) and, if the
+//     element also contains text, a "#text" key holding it.
+//
+// Namespaces are discarded, as are comments and processing instructions.
+func unmarshalXML(content []byte, o *options) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var result interface{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, newDecodeError(FormatXML, content, err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if result != nil {
+				return nil, newDecodeError(FormatXML, content, errors.New("multiple root elements"))
+			}
+			value, err := decodeXMLElement(decoder, token, o)
+			if err != nil {
+				return nil, newDecodeError(FormatXML, content, err)
+			}
+			result = newObject(o)
+			setKey(result, token.Name.Local, value)
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return nil, newDecodeError(FormatXML, content, errors.New("text outside of the root element"))
+			}
+		}
+	}
+	if result == nil {
+		return nil, newDecodeError(FormatXML, content, errors.New("no root element"))
+	}
+	return result, nil
+}
+
+// decodeXMLElement decodes the element that starts with the given token,
+// consuming all tokens up to and including its end.
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement, o *options) (interface{}, error) {
+	object := newObject(o)
+	fields := false
+	for _, attribute := range start.Attr {
+		if attribute.Name.Space == "xmlns" || attribute.Name.Local == "xmlns" {
+			continue
+		}
+		setKey(object, xmlAttributePrefix+attribute.Name.Local, attribute.Value)
+		fields = true
+	}
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			value, err := decodeXMLElement(decoder, token, o)
+			if err != nil {
+				return nil, err
+			}
+			if existing, ok := getKey(object, token.Name.Local); !ok {
+				setKey(object, token.Name.Local, value)
+			} else if array, ok := existing.([]interface{}); ok {
+				setKey(object, token.Name.Local, append(array, value))
+			} else {
+				setKey(object, token.Name.Local, []interface{}{existing, value})
+			}
+			fields = true
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			value := strings.TrimSpace(text.String())
+			if !fields {
+				return value, nil
+			}
+			if value != "" {
+				setKey(object, xmlTextKey, value)
+			}
+			return object, nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package rawdata
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalXML(t *testing.T) {
+	result, err := Unmarshal("@./test/config.xml")
+	if err != nil {
+		t.Fatalf("error unmarshalling XML: %v", err)
+	}
+	expected := map[string]interface{}{
+		"config": map[string]interface{}{
+			"-version": "2",
+			"name":     "My Application",
+			"server":   map[string]interface{}{"-host": "localhost", "-port": "8080"},
+			"user":     []interface{}{"John", "Jane"},
+			"description": map[string]interface{}{
+				"-lang": "en",
+				"#text": "A sample application",
+			},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: %v", result)
+	}
+
+	result, err = Unmarshal("<a><b>1</b><c/></a>")
+	if err != nil {
+		t.Fatalf("error unmarshalling inline XML: %v", err)
+	}
+	expected = map[string]interface{}{"a": map[string]interface{}{"b": "1", "c": ""}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid inline result: %v", result)
+	}
+
+	for _, input := range []string{"<a></b>", "<a/><b/>", "<a/>text", "xml:"} {
+		if _, err := Unmarshal(input); err == nil {
+			t.Errorf("no error unmarshalling %q", input)
+		}
+	}
+}
+
+func TestUnmarshalXMLInto(t *testing.T) {
+	target := struct {
+		XMLName xml.Name `xml:"config"`
+		Name    string   `xml:"name"`
+		Users   []string `xml:"user"`
+		Server  struct {
+			Port int `xml:"port,attr"`
+		} `xml:"server"`
+	}{}
+	if err := UnmarshalInto("@./test/config.xml", &target); err != nil {
+		t.Fatalf("error unmarshalling XML into struct: %v", err)
+	}
+	if target.Name != "My Application" || len(target.Users) != 2 || target.Server.Port != 8080 {
+		t.Errorf("invalid target: %+v", target)
+	}
+}
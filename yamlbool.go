@@ -0,0 +1,48 @@
+package rawdata
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// yaml11Bools maps the YAML 1.1 boolean tokens that YAML 1.2 treats as
+// strings to their values; they are the same that the YAML library accepts
+// when decoding into bool fields.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "on": true, "On": true, "ON": true,
+	"n": false, "N": false, "no": false, "No": false, "NO": false, "off": false, "Off": false, "OFF": false,
+}
+
+// convertYAMLBools retags the unquoted scalars in the node tree that are YAML
+// 1.1 booleans (see WithYAMLBoolCompat) as YAML 1.2 booleans, leaving the keys
+// of mappings alone; it returns whether anything changed. Visited nodes are
+// skipped, since they can be reached again through aliases.
+func convertYAMLBools(node *yaml.Node, visited map[*yaml.Node]bool) bool {
+	if visited[node] {
+		return false
+	}
+	visited[node] = true
+	changed := false
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if value, ok := yaml11Bools[node.Value]; ok && node.Style == 0 && node.Tag == "!!str" {
+			node.Tag = "!!bool"
+			if value {
+				node.Value = "true"
+			} else {
+				node.Value = "false"
+			}
+			changed = true
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			changed = convertYAMLBools(node.Content[i], visited) || changed
+		}
+	case yaml.AliasNode:
+		changed = convertYAMLBools(node.Alias, visited)
+	default:
+		for _, child := range node.Content {
+			changed = convertYAMLBools(child, visited) || changed
+		}
+	}
+	return changed
+}
@@ -0,0 +1,56 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalYAMLBoolCompat(t *testing.T) {
+	input := "---\nenabled: yes\ndebug: Off\nquoted: \"on\"\ntagged: !!str NO\nname: Norway\nlist: [Y, n, true]\nno: value\nbase: &base {flag: ON}\nderived: *base\n"
+	for _, opts := range [][]Option{nil, {WithOrderedMaps(true)}, {WithUseNumber(true)}} {
+		result, err := Unmarshal(input, append(opts, WithYAMLBoolCompat(true))...)
+		if err != nil {
+			t.Fatalf("error unmarshalling: %v", err)
+		}
+		for path, expected := range map[string]interface{}{
+			"enabled":      true,
+			"debug":        false,
+			"quoted":       "on",
+			"tagged":       "NO",
+			"name":         "Norway",
+			"list.0":       true,
+			"list.1":       false,
+			"list.2":       true,
+			"no":           "value",
+			"derived.flag": true,
+		} {
+			if value, ok := GetPath(result, path); !ok || value != expected {
+				t.Errorf("invalid value at %q: expected %v, got %v", path, expected, value)
+			}
+		}
+	}
+	result, err := Unmarshal(input)
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	if value, _ := GetPath(result, "enabled"); value != "yes" {
+		t.Errorf("invalid value without compatibility: %v", value)
+	}
+}
+
+func TestUnmarshalIntoYAMLBoolCompat(t *testing.T) {
+	type config struct {
+		Enabled bool                   `yaml:"enabled"`
+		Mode    string                 `yaml:"mode"`
+		Extra   interface{}            `yaml:"extra"`
+		Flags   map[string]interface{} `yaml:"flags"`
+	}
+	result := config{}
+	if err := UnmarshalInto("---\nenabled: on\nmode: off\nextra: yes\nflags: {a: NO, b: maybe}\n", &result, WithYAMLBoolCompat(true)); err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	expected := config{Enabled: true, Mode: "off", Extra: true, Flags: map[string]interface{}{"a": false, "b": "maybe"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("invalid result: expected %+v, got %+v", expected, result)
+	}
+}
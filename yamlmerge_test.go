@@ -0,0 +1,52 @@
+package rawdata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithYAMLMergeKeys(t *testing.T) {
+	expected := map[string]interface{}{
+		// explicit keys win, wherever the merge key is
+		"development": map[string]interface{}{"adapter": "postgres", "host": "localhost", "database": "dev", "pool": 2},
+		// earlier mappings in the sequence win over later ones
+		"production": map[string]interface{}{"adapter": "postgres", "host": "db.example.com", "database": "prod", "pool": 20, "timeout": 30},
+		// merged mappings can have merge keys in turn
+		"test": map[string]interface{}{"adapter": "postgres", "host": "db.example.com", "database": "test", "pool": 20, "timeout": 30},
+	}
+	for _, opts := range [][]Option{{WithYAMLMergeKeys(true)}, {WithYAMLMergeKeys(true), WithOrderedMaps(true)}} {
+		result, err := Unmarshal("@./test/merge-keys.yaml", opts...)
+		if err != nil {
+			t.Fatalf("error unmarshalling: %v", err)
+		}
+		if ordered, ok := result.(*OrderedMap); ok {
+			result = plainValue(ordered)
+		}
+		for name, environment := range expected {
+			if value, _ := GetPath(result, name); !reflect.DeepEqual(value, environment) {
+				t.Errorf("invalid %s: expected %v, got %v", name, environment, value)
+			}
+		}
+	}
+	ordered, err := Unmarshal("@./test/merge-keys.yaml", WithYAMLMergeKeys(true), WithOrderedMaps(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling: %v", err)
+	}
+	production, _ := GetPath(ordered, "production")
+	if keys := production.(*OrderedMap).Keys(); !reflect.DeepEqual(keys, []string{"pool", "timeout", "adapter", "database", "host"}) {
+		t.Errorf("invalid key order: %v", keys)
+	}
+	for _, input := range []string{"---\na: 1\nb:\n  <<: 42\n", "---\na: &a [1]\nb:\n  <<: [*a]\n"} {
+		if _, err := Unmarshal(input, WithYAMLMergeKeys(true)); err == nil {
+			t.Errorf("expected error for invalid merge in %q", input)
+		}
+	}
+	// a quoted key is not a merge key
+	result, err := Unmarshal("---\nb:\n  \"<<\": {c: 1}\n", WithYAMLMergeKeys(true))
+	if err != nil {
+		t.Fatalf("error unmarshalling quoted key: %v", err)
+	}
+	if value, _ := GetPath(result, "b.<<.c"); value != 1 {
+		t.Errorf("invalid quoted key: %v", result)
+	}
+}